@@ -11,8 +11,15 @@ import (
 
 var db *sql.DB
 
-// ConnectDB connects to the PostgreSQL database.
+// ConnectDB connects to the PostgreSQL database and makes sure its schema
+// is up to date with the embedded migrations.
 func ConnectDB() {
+	OpenDB()
+	ensureSchemaUpToDate(os.Getenv("DB_AUTO_MIGRATE") == "true")
+}
+
+// OpenDB connects to the PostgreSQL database without checking migrations.
+func OpenDB() {
 	connectionString := os.Getenv("DATABASE_URL")
 	if connectionString == "" {
 		log.Fatal("DATABASE_URL environment variable is not set")
@@ -0,0 +1,319 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/daffashafwan/tadarus-yuk/migrations"
+)
+
+const createSchemaMigrationsTable = `
+    CREATE TABLE IF NOT EXISTS schema_migrations (
+        version BIGINT PRIMARY KEY,
+        applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    )
+`
+
+// Migration is a single up/down pair from the migrations directory.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus tells whether a migration has been applied.
+type MigrationStatus struct {
+	Migration
+	Applied bool
+}
+
+// Migrator applies the embedded migrations to a database.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator loads the migrations embedded in the binary.
+func NewMigrator(conn *sql.DB) (*Migrator, error) {
+	loaded, err := loadMigrations(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: conn, migrations: loaded}, nil
+}
+
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, file := range files {
+		var direction string
+		switch {
+		case strings.HasSuffix(file, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(file, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(file, "."+direction+".sql")
+		parts := strings.SplitN(base, "_", 2)
+		version, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil || len(parts) != 2 {
+			return nil, fmt.Errorf("invalid migration file name %s", file)
+		}
+
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: parts[1]}
+			byVersion[version] = migration
+		}
+		if direction == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	result := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		result = append(result, *migration)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+	return result, nil
+}
+
+func (m *Migrator) appliedVersions() (map[int64]bool, error) {
+	if _, err := m.db.Exec(createSchemaMigrationsTable); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.db.Query("SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]bool)
+	for rows.Next() {
+		var version int64
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+// Status lists every known migration and whether it has been applied.
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	applied, err := m.appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		statuses = append(statuses, MigrationStatus{Migration: migration, Applied: applied[migration.Version]})
+	}
+	return statuses, nil
+}
+
+// Pending returns the migrations that have not been applied yet.
+func (m *Migrator) Pending() ([]Migration, error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, status := range statuses {
+		if !status.Applied {
+			pending = append(pending, status.Migration)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies every pending migration.
+func (m *Migrator) Up() error {
+	if len(m.migrations) == 0 {
+		return nil
+	}
+	return m.Goto(m.migrations[len(m.migrations)-1].Version)
+}
+
+// Down rolls back the most recently applied migration.
+func (m *Migrator) Down() error {
+	applied, err := m.appliedVersions()
+	if err != nil {
+		return err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		if applied[m.migrations[i].Version] {
+			return m.run(m.migrations[i], false)
+		}
+	}
+	return errors.New("no migration to roll back")
+}
+
+// Goto migrates up or down until the given version is the latest applied one.
+// Version 0 rolls back everything.
+func (m *Migrator) Goto(version int64) error {
+	if version != 0 && !m.known(version) {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+
+	applied, err := m.appliedVersions()
+	if err != nil {
+		return err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if migration.Version > version && applied[migration.Version] {
+			if err := m.run(migration, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, migration := range m.migrations {
+		if migration.Version <= version && !applied[migration.Version] {
+			if err := m.run(migration, true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) known(version int64) bool {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Migrator) run(migration Migration, up bool) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	direction := "up"
+	script := migration.Up
+	bookkeeping := "INSERT INTO schema_migrations (version) VALUES ($1)"
+	if !up {
+		direction = "down"
+		script = migration.Down
+		bookkeeping = "DELETE FROM schema_migrations WHERE version = $1"
+		if script == "" {
+			return fmt.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+		}
+	}
+
+	if _, err := tx.Exec(script); err != nil {
+		return fmt.Errorf("migration %d_%s %s: %w", migration.Version, migration.Name, direction, err)
+	}
+	if _, err := tx.Exec(bookkeeping, migration.Version); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	log.Printf("Migrated %s %d_%s", direction, migration.Version, migration.Name)
+	return nil
+}
+
+// RunMigrationCommand runs one of the up, down, status or goto commands.
+func RunMigrationCommand(args []string) error {
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return errors.New("usage: migrate up|down|status|goto VERSION")
+	}
+
+	switch args[0] {
+	case "up":
+		return migrator.Up()
+	case "down":
+		return migrator.Down()
+	case "goto":
+		if len(args) < 2 {
+			return errors.New("usage: migrate goto VERSION")
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %s", args[1])
+		}
+		return migrator.Goto(version)
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied"
+			}
+			fmt.Printf("%-8s %d_%s\n", state, status.Version, status.Name)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %s", args[0])
+	}
+}
+
+// ensureSchemaUpToDate stops the server when migrations are pending, unless
+// DB_AUTO_MIGRATE is enabled, in which case they are applied right away.
+func ensureSchemaUpToDate(autoMigrate bool) {
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		log.Fatal("Error loading migrations:", err)
+	}
+
+	pending, err := migrator.Pending()
+	if err != nil {
+		log.Fatal("Error checking migrations:", err)
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	if !autoMigrate {
+		log.Fatalf("Database schema is behind by %d migration(s), run `migrate up` or set DB_AUTO_MIGRATE=true", len(pending))
+	}
+
+	if err := migrator.Up(); err != nil {
+		log.Fatal("Error applying migrations:", err)
+	}
+}
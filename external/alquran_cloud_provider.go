@@ -0,0 +1,123 @@
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/daffashafwan/tadarus-yuk/external/dto"
+)
+
+const (
+	alQuranCloudPageEndpoint  = "/page/"
+	alQuranCloudSurahEndpoint = "/surah/"
+)
+
+// alQuranCloudProvider talks to the alquran.cloud v1 API and maps its
+// responses to the quran.com shape.
+type alQuranCloudProvider struct {
+	baseURL string
+}
+
+func NewAlQuranCloudProvider(baseURL string) QuranProvider {
+	return &alQuranCloudProvider{baseURL: baseURL}
+}
+
+func (p *alQuranCloudProvider) Name() string {
+	return alQuranCloudProviderName
+}
+
+func (p *alQuranCloudProvider) GetPage(ctx context.Context, pageNum string) (dto.QuranAPIPage, error) {
+	page, err := getAlQuranCloud[dto.AlQuranCloudPage](ctx, p.baseURL+alQuranCloudPageEndpoint+pageNum)
+	if err != nil {
+		return dto.QuranAPIPage{}, err
+	}
+
+	verses := make([]dto.Verse, 0, len(page.Ayahs))
+	for _, ayah := range page.Ayahs {
+		if ayah.Surah == nil {
+			return dto.QuranAPIPage{}, fmt.Errorf("alquran.cloud page %s: ayah %d without surah", pageNum, ayah.Number)
+		}
+		verses = append(verses, dto.Verse{
+			ID:              ayah.Number,
+			VerseNumber:     ayah.NumberInSurah,
+			VerseKey:        strconv.Itoa(ayah.Surah.Number) + ":" + strconv.Itoa(ayah.NumberInSurah),
+			HizbNumber:      (ayah.HizbQuarter-1)/4 + 1,
+			RubElHizbNumber: ayah.HizbQuarter,
+			RukuNumber:      ayah.Ruku,
+			ManzilNumber:    ayah.Manzil,
+			SajdahNumber:    alQuranCloudSajdahNumber(ayah.Sajda),
+			PageNumber:      ayah.Page,
+			JuzNumber:       ayah.Juz,
+		})
+	}
+
+	return dto.QuranAPIPage{
+		Verses: verses,
+		Pagination: dto.Pagination{
+			PerPage:      len(verses),
+			CurrentPage:  1,
+			TotalPages:   1,
+			TotalRecords: len(verses),
+		},
+	}, nil
+}
+
+func (p *alQuranCloudProvider) GetChapter(ctx context.Context, chapter string) (dto.QuranAPIChapter, error) {
+	surah, err := getAlQuranCloud[dto.AlQuranCloudSurah](ctx, p.baseURL+alQuranCloudSurahEndpoint+chapter)
+	if err != nil {
+		return dto.QuranAPIChapter{}, err
+	}
+
+	result := dto.Chapter{
+		ID:              surah.Number,
+		RevelationPlace: alQuranCloudRevelationPlace(surah.RevelationType),
+		BismillahPre:    surah.Number != 1 && surah.Number != 9,
+		NameSimple:      surah.EnglishName,
+		NameComplex:     surah.EnglishName,
+		NameArabic:      surah.Name,
+		VersesCount:     surah.NumberOfAyahs,
+	}
+	result.TranslatedName.LanguageName = "english"
+	result.TranslatedName.Name = surah.EnglishNameTranslation
+	if len(surah.Ayahs) > 0 {
+		result.Pages = []int{surah.Ayahs[0].Page, surah.Ayahs[len(surah.Ayahs)-1].Page}
+	}
+
+	return dto.QuranAPIChapter{Chapter: result}, nil
+}
+
+// GetChapterInfo is not available, alquran.cloud has no surah descriptions.
+func (p *alQuranCloudProvider) GetChapterInfo(ctx context.Context, chapter string) (dto.QuranAPIChapterInfo, error) {
+	return dto.QuranAPIChapterInfo{}, ErrNotSupported
+}
+
+func getAlQuranCloud[T any](ctx context.Context, url string) (T, error) {
+	var res dto.AlQuranCloudResponse[T]
+	if err := retryAPIRequest(ctx, url, &res); err != nil {
+		return res.Data, err
+	}
+	if res.Code != http.StatusOK {
+		return res.Data, fmt.Errorf("alquran.cloud %s: %d %s", url, res.Code, res.Status)
+	}
+	return res.Data, nil
+}
+
+// alQuranCloudSajdahNumber reads the sajda field, which is false for a
+// regular ayah and an object for a verse of prostration.
+func alQuranCloudSajdahNumber(raw json.RawMessage) *int {
+	var sajda dto.AlQuranCloudSajda
+	if err := json.Unmarshal(raw, &sajda); err != nil || sajda.ID == 0 {
+		return nil
+	}
+	return &sajda.ID
+}
+
+func alQuranCloudRevelationPlace(revelationType string) string {
+	if revelationType == "Medinan" {
+		return "madinah"
+	}
+	return "makkah"
+}
@@ -0,0 +1,42 @@
+package dto
+
+import "encoding/json"
+
+type AlQuranCloudResponse[T any] struct {
+	Code   int    `json:"code"`
+	Status string `json:"status"`
+	Data   T      `json:"data"`
+}
+
+type AlQuranCloudPage struct {
+	Number int                `json:"number"`
+	Ayahs  []AlQuranCloudAyah `json:"ayahs"`
+}
+
+type AlQuranCloudAyah struct {
+	Number        int                `json:"number"`
+	NumberInSurah int                `json:"numberInSurah"`
+	Juz           int                `json:"juz"`
+	Manzil        int                `json:"manzil"`
+	Page          int                `json:"page"`
+	Ruku          int                `json:"ruku"`
+	HizbQuarter   int                `json:"hizbQuarter"`
+	Sajda         json.RawMessage    `json:"sajda"`
+	Surah         *AlQuranCloudSurah `json:"surah,omitempty"`
+}
+
+type AlQuranCloudSurah struct {
+	Number                 int                `json:"number"`
+	Name                   string             `json:"name"`
+	EnglishName            string             `json:"englishName"`
+	EnglishNameTranslation string             `json:"englishNameTranslation"`
+	NumberOfAyahs          int                `json:"numberOfAyahs"`
+	RevelationType         string             `json:"revelationType"`
+	Ayahs                  []AlQuranCloudAyah `json:"ayahs,omitempty"`
+}
+
+type AlQuranCloudSajda struct {
+	ID          int  `json:"id"`
+	Recommended bool `json:"recommended"`
+	Obligatory  bool `json:"obligatory"`
+}
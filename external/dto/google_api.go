@@ -6,5 +6,6 @@ type CalendarEvent struct {
 	EventDescription string
 	StartDate        string
 	EndDate          string
+	Recurrence       string
 	Type             string
 }
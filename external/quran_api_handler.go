@@ -1,9 +1,12 @@
 package external
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
 	"os"
 	"strconv"
@@ -17,78 +20,221 @@ const (
 	infoEndpoint         = "/info"
 	languageQueryParam   = "?language=id"
 	versesByPageEndpoint = "/verses/by_page/"
+
+	defaultQuranAPITimeout       = 10 * time.Second
+	defaultQuranAPIRetryInterval = 500 * time.Millisecond
+	quranAPIMaxRetryInterval     = 30 * time.Second
 )
 
 var (
 	quranAPIURL           string
 	quranAPIMaxRetry      int
-	quranAPIRetryInterval int
+	quranAPIRetryInterval time.Duration
+	quranAPIClient        = &http.Client{Timeout: defaultQuranAPITimeout}
 )
 
+// QuranAPIError is returned when an upstream Quran API request fails after
+// all attempts.
+type QuranAPIError struct {
+	URL string
+	// StatusCode is 0 when no response was received.
+	StatusCode int
+	Attempts   int
+	Timeout    bool
+	Err        error
+}
+
+func (e *QuranAPIError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("quran api %s: status %d after %d attempt(s)", e.URL, e.StatusCode, e.Attempts)
+	}
+	return fmt.Sprintf("quran api %s: %v after %d attempt(s)", e.URL, e.Err, e.Attempts)
+}
+
+func (e *QuranAPIError) Unwrap() error {
+	return e.Err
+}
+
+// HTTPStatus is the status a handler should answer with, 504 when the
+// upstream timed out and 502 otherwise.
+func (e *QuranAPIError) HTTPStatus() int {
+	if e.Timeout {
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusBadGateway
+}
+
+// retryableStatusError is an upstream response worth retrying, 5xx or 429.
+type retryableStatusError struct {
+	statusCode int
+	retryAfter time.Duration
+}
+
+func (e *retryableStatusError) Error() string {
+	return "status " + strconv.Itoa(e.statusCode)
+}
+
 func getEnvAsInt(key string) int {
 	val, _ := strconv.Atoi(os.Getenv(key))
 	return val
 }
 
-func InitQuranAPI() {
+func initQuranAPIClient() {
 	quranAPIURL = os.Getenv("QURAN_RAPID_API_URL")
 	quranAPIMaxRetry = getEnvAsInt("QURAN_RAPID_MAX_RETRY")
-	quranAPIRetryInterval = getEnvAsInt("QURAN_RAPID_RETRY_INTERVAL")
+	quranAPIRetryInterval = defaultQuranAPIRetryInterval
+	if val := getEnvAsInt("QURAN_RAPID_RETRY_INTERVAL"); val > 0 {
+		quranAPIRetryInterval = time.Duration(val) * time.Millisecond
+	}
+	if val := getEnvAsInt("QURAN_API_TIMEOUT"); val > 0 {
+		quranAPIClient.Timeout = time.Duration(val) * time.Millisecond
+	}
 }
 
-func sendAPIRequest(endpoint string) ([]byte, error) {
-	url := quranAPIURL + endpoint
-	req, err := http.NewRequest("GET", url, nil)
+func sendAPIRequest(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	res, err := http.DefaultClient.Do(req)
+	res, err := quranAPIClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer res.Body.Close()
 
+	if res.StatusCode >= http.StatusInternalServerError || res.StatusCode == http.StatusTooManyRequests {
+		return nil, &retryableStatusError{statusCode: res.StatusCode, retryAfter: parseRetryAfter(res.Header.Get("Retry-After"))}
+	}
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return nil, &QuranAPIError{URL: url, StatusCode: res.StatusCode, Attempts: 1, Err: errors.New(res.Status)}
+	}
+
 	return io.ReadAll(res.Body)
 }
 
-func retryAPIRequest(endpoint string, result interface{}) error {
+// retryAPIRequest decodes the response of url into result. Network errors,
+// 5xx and 429 responses are retried up to QURAN_RAPID_MAX_RETRY attempts with
+// exponential backoff, or after the Retry-After the upstream asked for.
+func retryAPIRequest(ctx context.Context, url string, result interface{}) error {
 	maxTry := quranAPIMaxRetry
-	interval := time.Duration(quranAPIRetryInterval) * time.Millisecond
+	if maxTry < 1 {
+		maxTry = 1
+	}
 
+	var err error
 	for attempt := 1; attempt <= maxTry; attempt++ {
-		body, err := sendAPIRequest(endpoint)
+		var body []byte
+		body, err = sendAPIRequest(ctx, url)
 		if err == nil {
-			err = json.Unmarshal(body, result)
-			if err == nil {
-				return nil
+			if err = json.Unmarshal(body, result); err != nil {
+				return &QuranAPIError{URL: url, Attempts: attempt, Err: err}
 			}
+			return nil
 		}
 
-		if attempt == maxTry {
-			return errors.New("maximum retries reached")
+		var apiErr *QuranAPIError
+		if errors.As(err, &apiErr) {
+			apiErr.Attempts = attempt
+			return apiErr
+		}
+		if ctx.Err() != nil || attempt == maxTry {
+			return newQuranAPIError(ctx, url, attempt, err)
 		}
 
-		time.Sleep(interval)
+		wait := retryBackoff(attempt)
+		var statusErr *retryableStatusError
+		if errors.As(err, &statusErr) && statusErr.retryAfter > 0 {
+			wait = statusErr.retryAfter
+			if wait > quranAPIMaxRetryInterval {
+				wait = quranAPIMaxRetryInterval
+			}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return newQuranAPIError(ctx, url, attempt, err)
+		case <-timer.C:
+		}
+	}
+
+	return newQuranAPIError(ctx, url, maxTry, err)
+}
+
+func newQuranAPIError(ctx context.Context, url string, attempts int, err error) *QuranAPIError {
+	apiErr := &QuranAPIError{URL: url, Attempts: attempts, Err: err}
+
+	var statusErr *retryableStatusError
+	var netErr interface{ Timeout() bool }
+	if errors.As(err, &statusErr) {
+		apiErr.StatusCode = statusErr.statusCode
+	} else if errors.Is(ctx.Err(), context.DeadlineExceeded) || errors.Is(err, context.DeadlineExceeded) {
+		apiErr.Timeout = true
+	} else if errors.As(err, &netErr) && netErr.Timeout() {
+		apiErr.Timeout = true
+	}
+	if ctx.Err() != nil && apiErr.StatusCode == 0 {
+		apiErr.Err = ctx.Err()
+	}
+	return apiErr
+}
+
+// retryBackoff doubles the retry interval on every attempt, with equal
+// jitter so clients do not retry in lockstep.
+func retryBackoff(attempt int) time.Duration {
+	backoff := quranAPIRetryInterval
+	for i := 1; i < attempt && backoff < quranAPIMaxRetryInterval; i++ {
+		backoff *= 2
 	}
+	if backoff > quranAPIMaxRetryInterval {
+		backoff = quranAPIMaxRetryInterval
+	}
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as a date.
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return time.Until(date)
+	}
+	return 0
+}
+
+// quranComProvider talks to the quran.com v4 API.
+type quranComProvider struct {
+	baseURL string
+}
+
+func NewQuranComProvider(baseURL string) QuranProvider {
+	return &quranComProvider{baseURL: baseURL}
+}
 
-	return errors.New("maximum retries reached")
+func (p *quranComProvider) Name() string {
+	return quranComProviderName
 }
 
-func GetQuranAPIPages(pageNum string) (dto.QuranAPIPage, error) {
+func (p *quranComProvider) GetPage(ctx context.Context, pageNum string) (dto.QuranAPIPage, error) {
 	var quranAPIPage dto.QuranAPIPage
-	err := retryAPIRequest(versesByPageEndpoint+pageNum, &quranAPIPage)
+	err := retryAPIRequest(ctx, p.baseURL+versesByPageEndpoint+pageNum, &quranAPIPage)
 	return quranAPIPage, err
 }
 
-func GetQuranAPIChapter(chapter string) (dto.QuranAPIChapter, error) {
+func (p *quranComProvider) GetChapter(ctx context.Context, chapter string) (dto.QuranAPIChapter, error) {
 	var quranAPIChapter dto.QuranAPIChapter
-	err := retryAPIRequest(chaptersEndpoint+chapter+languageQueryParam, &quranAPIChapter)
+	err := retryAPIRequest(ctx, p.baseURL+chaptersEndpoint+chapter+languageQueryParam, &quranAPIChapter)
 	return quranAPIChapter, err
 }
 
-func GetQuranAPIChapterInfo(chapter string) (dto.QuranAPIChapterInfo, error) {
+func (p *quranComProvider) GetChapterInfo(ctx context.Context, chapter string) (dto.QuranAPIChapterInfo, error) {
 	var quranAPIChapterInfo dto.QuranAPIChapterInfo
-	err := retryAPIRequest(chaptersEndpoint+chapter+infoEndpoint+languageQueryParam, &quranAPIChapterInfo)
+	err := retryAPIRequest(ctx, p.baseURL+chaptersEndpoint+chapter+infoEndpoint+languageQueryParam, &quranAPIChapterInfo)
 	return quranAPIChapterInfo, err
 }
@@ -0,0 +1,328 @@
+package external
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/daffashafwan/tadarus-yuk/external/dto"
+	"github.com/daffashafwan/tadarus-yuk/internal/quran"
+)
+
+const (
+	defaultQuranCacheSize = 1000
+	defaultQuranCacheTTL  = 30 * 24 * time.Hour
+)
+
+// QuranCacheStore is the persistent tier behind the in-memory LRU.
+type QuranCacheStore interface {
+	// Get returns ok false when the key is missing or expired.
+	Get(key string) (value []byte, expiresAt time.Time, ok bool, err error)
+	Set(key string, value []byte, expiresAt time.Time) error
+}
+
+type QuranCacheStats struct {
+	MemoryHits     int64 `json:"memoryHits"`
+	PersistentHits int64 `json:"persistentHits"`
+	Misses         int64 `json:"misses"`
+	MemoryEntries  int   `json:"memoryEntries"`
+}
+
+// InitQuranCache wraps the configured provider with the response cache. The
+// persistent tier is chosen by QURAN_CACHE_STORE: postgres (default), disk
+// or none.
+func InitQuranCache(conn *sql.DB) {
+	size := getEnvAsInt("QURAN_CACHE_SIZE")
+	if size <= 0 {
+		size = defaultQuranCacheSize
+	}
+	ttl := defaultQuranCacheTTL
+	if hours := getEnvAsInt("QURAN_CACHE_TTL_HOURS"); hours > 0 {
+		ttl = time.Duration(hours) * time.Hour
+	}
+
+	var persistent QuranCacheStore
+	switch os.Getenv("QURAN_CACHE_STORE") {
+	case "", "postgres":
+		persistent = NewPostgresQuranCache(conn)
+	case "disk":
+		persistent = NewDiskQuranCache(os.Getenv("QURAN_CACHE_DIR"))
+	case "none":
+	default:
+		log.Fatalf("unknown quran cache store %q", os.Getenv("QURAN_CACHE_STORE"))
+	}
+
+	quranProvider = NewCachedQuranProvider(quranProvider, size, ttl, persistent)
+}
+
+// GetQuranCacheStats returns the cache counters, ok is false when the
+// provider is not cached.
+func GetQuranCacheStats() (QuranCacheStats, bool) {
+	cached, ok := quranProvider.(*cachedQuranProvider)
+	if !ok {
+		return QuranCacheStats{}, false
+	}
+	return cached.Stats(), true
+}
+
+// WarmQuranCache fetches every chapter, chapter info and page through the
+// cache so later requests do not reach the upstream provider.
+func WarmQuranCache(ctx context.Context) error {
+	var errs []error
+	for chapter := 1; chapter <= quran.TotalSurahs; chapter++ {
+		if _, err := GetQuranAPIChapter(ctx, strconv.Itoa(chapter)); err != nil {
+			errs = append(errs, err)
+		}
+		if _, err := GetQuranAPIChapterInfo(ctx, strconv.Itoa(chapter)); err != nil && !errors.Is(err, ErrNotSupported) {
+			errs = append(errs, err)
+		}
+	}
+	for page := 1; page <= quran.TotalPages; page++ {
+		if _, err := GetQuranAPIPages(ctx, strconv.Itoa(page)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type cachedQuranProvider struct {
+	next       QuranProvider
+	ttl        time.Duration
+	memory     *lruCache
+	persistent QuranCacheStore
+
+	memoryHits     atomic.Int64
+	persistentHits atomic.Int64
+	misses         atomic.Int64
+}
+
+// NewCachedQuranProvider caches the responses of next in an LRU of size
+// entries and, when persistent is not nil, in a persistent store.
+func NewCachedQuranProvider(next QuranProvider, size int, ttl time.Duration, persistent QuranCacheStore) QuranProvider {
+	return &cachedQuranProvider{
+		next:       next,
+		ttl:        ttl,
+		memory:     newLRUCache(size),
+		persistent: persistent,
+	}
+}
+
+func (p *cachedQuranProvider) Name() string {
+	return p.next.Name()
+}
+
+func (p *cachedQuranProvider) GetPage(ctx context.Context, pageNum string) (dto.QuranAPIPage, error) {
+	var quranAPIPage dto.QuranAPIPage
+	err := p.fetch("page:"+pageNum, &quranAPIPage, func() (interface{}, error) {
+		return p.next.GetPage(ctx, pageNum)
+	})
+	return quranAPIPage, err
+}
+
+func (p *cachedQuranProvider) GetChapter(ctx context.Context, chapter string) (dto.QuranAPIChapter, error) {
+	var quranAPIChapter dto.QuranAPIChapter
+	err := p.fetch("chapter:"+chapter, &quranAPIChapter, func() (interface{}, error) {
+		return p.next.GetChapter(ctx, chapter)
+	})
+	return quranAPIChapter, err
+}
+
+func (p *cachedQuranProvider) GetChapterInfo(ctx context.Context, chapter string) (dto.QuranAPIChapterInfo, error) {
+	var quranAPIChapterInfo dto.QuranAPIChapterInfo
+	err := p.fetch("chapter_info:"+chapter, &quranAPIChapterInfo, func() (interface{}, error) {
+		return p.next.GetChapterInfo(ctx, chapter)
+	})
+	return quranAPIChapterInfo, err
+}
+
+func (p *cachedQuranProvider) Stats() QuranCacheStats {
+	return QuranCacheStats{
+		MemoryHits:     p.memoryHits.Load(),
+		PersistentHits: p.persistentHits.Load(),
+		Misses:         p.misses.Load(),
+		MemoryEntries:  p.memory.Len(),
+	}
+}
+
+// fetch decodes the cached value of key into result, or calls get and
+// stores its response in both tiers. Failed responses are never cached.
+func (p *cachedQuranProvider) fetch(key string, result interface{}, get func() (interface{}, error)) error {
+	if value, ok := p.memory.Get(key, time.Now()); ok {
+		p.memoryHits.Add(1)
+		return json.Unmarshal(value, result)
+	}
+
+	if p.persistent != nil {
+		value, expiresAt, ok, err := p.persistent.Get(key)
+		if err != nil {
+			log.Printf("[QuranCache] error get %s : %v", key, err.Error())
+		} else if ok {
+			p.persistentHits.Add(1)
+			p.memory.Set(key, value, expiresAt)
+			return json.Unmarshal(value, result)
+		}
+	}
+
+	p.misses.Add(1)
+	res, err := get()
+	if err != nil {
+		return err
+	}
+	value, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(p.ttl)
+	p.memory.Set(key, value, expiresAt)
+	if p.persistent != nil {
+		if err := p.persistent.Set(key, value, expiresAt); err != nil {
+			log.Printf("[QuranCache] error set %s : %v", key, err.Error())
+		}
+	}
+	return json.Unmarshal(value, result)
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+type lruCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+func newLRUCache(size int) *lruCache {
+	return &lruCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (c *lruCache) Get(key string, now time.Time) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*lruEntry)
+	if !now.Before(entry.expiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return entry.value, true
+}
+
+func (c *lruCache) Set(key string, value []byte, expiresAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value = &lruEntry{key: key, value: value, expiresAt: expiresAt}
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+func (c *lruCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+type postgresQuranCache struct {
+	conn *sql.DB
+}
+
+func NewPostgresQuranCache(conn *sql.DB) QuranCacheStore {
+	return &postgresQuranCache{conn: conn}
+}
+
+func (c *postgresQuranCache) Get(key string) ([]byte, time.Time, bool, error) {
+	var value string
+	var expiresAt time.Time
+	err := c.conn.QueryRow("SELECT value, expires_at FROM quran_api_cache WHERE cache_key = $1 AND expires_at > $2", key, time.Now()).Scan(&value, &expiresAt)
+	if err == sql.ErrNoRows {
+		return nil, time.Time{}, false, nil
+	} else if err != nil {
+		return nil, time.Time{}, false, err
+	}
+	return []byte(value), expiresAt, true, nil
+}
+
+func (c *postgresQuranCache) Set(key string, value []byte, expiresAt time.Time) error {
+	_, err := c.conn.Exec(`INSERT INTO quran_api_cache (cache_key, value, expires_at, updated_at) VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		ON CONFLICT (cache_key) DO UPDATE SET value = EXCLUDED.value, expires_at = EXCLUDED.expires_at, updated_at = CURRENT_TIMESTAMP`,
+		key, string(value), expiresAt)
+	return err
+}
+
+// diskQuranCache keeps one file per key, the expiry is the file mtime.
+type diskQuranCache struct {
+	dir string
+}
+
+func NewDiskQuranCache(dir string) QuranCacheStore {
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "tadarus-quran-cache")
+	}
+	return &diskQuranCache{dir: dir}
+}
+
+func (c *diskQuranCache) path(key string) string {
+	return filepath.Join(c.dir, strings.ReplaceAll(key, ":", "_")+".json")
+}
+
+func (c *diskQuranCache) Get(key string) ([]byte, time.Time, bool, error) {
+	info, err := os.Stat(c.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, time.Time{}, false, nil
+	} else if err != nil {
+		return nil, time.Time{}, false, err
+	}
+	if !time.Now().Before(info.ModTime()) {
+		return nil, time.Time{}, false, nil
+	}
+
+	value, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, time.Time{}, false, err
+	}
+	return value, info.ModTime(), true, nil
+}
+
+func (c *diskQuranCache) Set(key string, value []byte, expiresAt time.Time) error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.path(key), value, 0o644); err != nil {
+		return err
+	}
+	return os.Chtimes(c.path(key), expiresAt, expiresAt)
+}
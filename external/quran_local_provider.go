@@ -0,0 +1,133 @@
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/daffashafwan/tadarus-yuk/external/dto"
+	"github.com/daffashafwan/tadarus-yuk/internal/quran"
+)
+
+// localFileProvider reads quran.com shaped responses from a directory laid
+// out like the API, pages/{page}.json, chapters/{chapter}.json and
+// chapters/{chapter}/info.json. Pages and chapters missing from the
+// directory are built from the embedded dataset.
+type localFileProvider struct {
+	dir string
+}
+
+func NewLocalFileProvider(dir string) QuranProvider {
+	return &localFileProvider{dir: dir}
+}
+
+func (p *localFileProvider) Name() string {
+	return localFileProviderName
+}
+
+func (p *localFileProvider) GetPage(ctx context.Context, pageNum string) (dto.QuranAPIPage, error) {
+	var quranAPIPage dto.QuranAPIPage
+	found, err := p.readFile(&quranAPIPage, "pages", pageNum+".json")
+	if found || err != nil {
+		return quranAPIPage, err
+	}
+
+	page, err := strconv.Atoi(pageNum)
+	if err != nil {
+		return quranAPIPage, err
+	}
+	verses, err := quran.GetPageVerses(page)
+	if err != nil {
+		return quranAPIPage, err
+	}
+
+	sajdahNumbers := make(map[string]int)
+	for i, verse := range quran.GetSajdahVerses() {
+		sajdahNumbers[verse.Key] = i + 1
+	}
+
+	for _, verse := range verses {
+		apiVerse := dto.Verse{
+			VerseNumber:     verse.Number,
+			VerseKey:        verse.Key,
+			HizbNumber:      verse.Hizb,
+			RubElHizbNumber: verse.RubElHizb,
+			RukuNumber:      verse.Ruku,
+			ManzilNumber:    verse.Manzil,
+			PageNumber:      verse.Page,
+			JuzNumber:       verse.Juz,
+		}
+		if number, ok := sajdahNumbers[verse.Key]; ok {
+			apiVerse.SajdahNumber = &number
+		}
+		quranAPIPage.Verses = append(quranAPIPage.Verses, apiVerse)
+	}
+	quranAPIPage.Pagination = dto.Pagination{
+		PerPage:      len(verses),
+		CurrentPage:  1,
+		TotalPages:   1,
+		TotalRecords: len(verses),
+	}
+	return quranAPIPage, nil
+}
+
+func (p *localFileProvider) GetChapter(ctx context.Context, chapter string) (dto.QuranAPIChapter, error) {
+	var quranAPIChapter dto.QuranAPIChapter
+	found, err := p.readFile(&quranAPIChapter, "chapters", chapter+".json")
+	if found || err != nil {
+		return quranAPIChapter, err
+	}
+
+	number, err := strconv.Atoi(chapter)
+	if err != nil {
+		return quranAPIChapter, err
+	}
+	surah, err := quran.GetSurah(number)
+	if err != nil {
+		return quranAPIChapter, err
+	}
+
+	quranAPIChapter.Chapter = dto.Chapter{
+		ID:              surah.Number,
+		RevelationPlace: surah.RevelationPlace,
+		RevelationOrder: surah.RevelationOrder,
+		BismillahPre:    surah.Number != 1 && surah.Number != 9,
+		NameSimple:      surah.Name,
+		NameComplex:     surah.Name,
+		NameArabic:      surah.NameArabic,
+		VersesCount:     surah.VersesCount,
+		Pages:           []int{surah.FirstPage, surah.LastPage},
+	}
+	quranAPIChapter.Chapter.TranslatedName.LanguageName = "english"
+	quranAPIChapter.Chapter.TranslatedName.Name = surah.NameEnglish
+	return quranAPIChapter, nil
+}
+
+func (p *localFileProvider) GetChapterInfo(ctx context.Context, chapter string) (dto.QuranAPIChapterInfo, error) {
+	var quranAPIChapterInfo dto.QuranAPIChapterInfo
+	found, err := p.readFile(&quranAPIChapterInfo, "chapters", chapter, "info.json")
+	if err == nil && !found {
+		err = ErrNotSupported
+	}
+	return quranAPIChapterInfo, err
+}
+
+// readFile decodes a response file, found is false when the file does not
+// exist or no directory is configured.
+func (p *localFileProvider) readFile(result interface{}, elem ...string) (bool, error) {
+	if p.dir == "" {
+		return false, nil
+	}
+
+	body, err := os.ReadFile(filepath.Join(append([]string{p.dir}, elem...)...))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return true, json.Unmarshal(body, result)
+}
@@ -0,0 +1,138 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/daffashafwan/tadarus-yuk/external/dto"
+)
+
+const (
+	quranComProviderName      = "quran.com"
+	alQuranCloudProviderName  = "alquran.cloud"
+	localFileProviderName     = "local"
+	defaultAlQuranCloudAPIURL = "https://api.alquran.cloud/v1"
+)
+
+// ErrNotSupported is returned by a provider that has no data for a request,
+// the fallback chain moves on to the next provider.
+var ErrNotSupported = errors.New("not supported by quran provider")
+
+// QuranProvider serves Quran pages and chapters in the quran.com response
+// shape, whatever the backend behind it.
+type QuranProvider interface {
+	Name() string
+	GetPage(ctx context.Context, pageNum string) (dto.QuranAPIPage, error)
+	GetChapter(ctx context.Context, chapter string) (dto.QuranAPIChapter, error)
+	GetChapterInfo(ctx context.Context, chapter string) (dto.QuranAPIChapterInfo, error)
+}
+
+var quranProvider QuranProvider
+
+func InitQuranAPI() {
+	initQuranAPIClient()
+
+	providers, err := newQuranProviders(os.Getenv("QURAN_PROVIDERS"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	quranProvider = NewFallbackQuranProvider(providers...)
+}
+
+// newQuranProviders builds the providers listed in a comma separated config,
+// in the order they should be tried. An empty config keeps quran.com only.
+func newQuranProviders(config string) ([]QuranProvider, error) {
+	if strings.TrimSpace(config) == "" {
+		config = quranComProviderName
+	}
+
+	var providers []QuranProvider
+	for _, name := range strings.Split(config, ",") {
+		switch strings.TrimSpace(name) {
+		case quranComProviderName:
+			providers = append(providers, NewQuranComProvider(quranAPIURL))
+		case alQuranCloudProviderName:
+			url := os.Getenv("QURAN_ALQURAN_CLOUD_API_URL")
+			if url == "" {
+				url = defaultAlQuranCloudAPIURL
+			}
+			providers = append(providers, NewAlQuranCloudProvider(url))
+		case localFileProviderName:
+			providers = append(providers, NewLocalFileProvider(os.Getenv("QURAN_LOCAL_DIR")))
+		default:
+			return nil, fmt.Errorf("unknown quran provider %q", name)
+		}
+	}
+	return providers, nil
+}
+
+type fallbackQuranProvider struct {
+	providers []QuranProvider
+}
+
+// NewFallbackQuranProvider tries every provider in order and returns the
+// first successful response.
+func NewFallbackQuranProvider(providers ...QuranProvider) QuranProvider {
+	if len(providers) == 1 {
+		return providers[0]
+	}
+	return &fallbackQuranProvider{providers: providers}
+}
+
+func (p *fallbackQuranProvider) Name() string {
+	names := make([]string, 0, len(p.providers))
+	for _, provider := range p.providers {
+		names = append(names, provider.Name())
+	}
+	return strings.Join(names, ",")
+}
+
+func (p *fallbackQuranProvider) GetPage(ctx context.Context, pageNum string) (dto.QuranAPIPage, error) {
+	return tryQuranProviders(p.providers, func(provider QuranProvider) (dto.QuranAPIPage, error) {
+		return provider.GetPage(ctx, pageNum)
+	})
+}
+
+func (p *fallbackQuranProvider) GetChapter(ctx context.Context, chapter string) (dto.QuranAPIChapter, error) {
+	return tryQuranProviders(p.providers, func(provider QuranProvider) (dto.QuranAPIChapter, error) {
+		return provider.GetChapter(ctx, chapter)
+	})
+}
+
+func (p *fallbackQuranProvider) GetChapterInfo(ctx context.Context, chapter string) (dto.QuranAPIChapterInfo, error) {
+	return tryQuranProviders(p.providers, func(provider QuranProvider) (dto.QuranAPIChapterInfo, error) {
+		return provider.GetChapterInfo(ctx, chapter)
+	})
+}
+
+func tryQuranProviders[T any](providers []QuranProvider, get func(QuranProvider) (T, error)) (T, error) {
+	var result T
+	errs := make([]error, 0, len(providers))
+	for _, provider := range providers {
+		res, err := get(provider)
+		if err == nil {
+			return res, nil
+		}
+		if !errors.Is(err, ErrNotSupported) {
+			log.Printf("[QuranProvider] %s failed, trying next provider : %v", provider.Name(), err.Error())
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+	}
+	return result, errors.Join(errs...)
+}
+
+func GetQuranAPIPages(ctx context.Context, pageNum string) (dto.QuranAPIPage, error) {
+	return quranProvider.GetPage(ctx, pageNum)
+}
+
+func GetQuranAPIChapter(ctx context.Context, chapter string) (dto.QuranAPIChapter, error) {
+	return quranProvider.GetChapter(ctx, chapter)
+}
+
+func GetQuranAPIChapterInfo(ctx context.Context, chapter string) (dto.QuranAPIChapterInfo, error) {
+	return quranProvider.GetChapterInfo(ctx, chapter)
+}
@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/dto"
+	"github.com/daffashafwan/tadarus-yuk/internal/helpers"
+	"github.com/daffashafwan/tadarus-yuk/internal/quran"
+	"github.com/daffashafwan/tadarus-yuk/internal/storage"
+	"github.com/gorilla/mux"
+)
+
+// GetBookmark returns where the user should continue reading. A pinned
+// bookmark wins until a page is read after it was pinned, then the last
+// position read in the active targets is returned.
+func GetBookmark(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	user, err := getUserByUsername(vars["id"])
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Error get user", nil)
+		return
+	}
+
+	bookmark, found, err := lastReadBookmark(user.ID)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error get bookmark", nil)
+		return
+	}
+
+	pin, err := store.Bookmarks.Get(user.ID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error get bookmark", nil)
+		return
+	}
+	if err == nil && (!found || !pin.UpdatedAt.Before(bookmark.UpdatedAt)) {
+		bookmark, err = pinnedBookmark(pin)
+		if err != nil {
+			helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error get bookmark", nil)
+			return
+		}
+		found = true
+	}
+
+	if !found {
+		helpers.ResponseJSON(w, nil, http.StatusNotFound, "no bookmark yet", nil)
+		return
+	}
+	helpers.ResponseJSON(w, nil, http.StatusOK, "SUCCESS", bookmark)
+}
+
+// PinBookmark pins a page, or a verse with verseKey, optionally in one of
+// the user's targets.
+func PinBookmark(w http.ResponseWriter, r *http.Request) {
+	var pin dto.BookmarkPin
+	err := json.NewDecoder(r.Body).Decode(&pin)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	vars := mux.Vars(r)
+	user, err := getUserByUsername(vars["id"])
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Error get user", nil)
+		return
+	}
+
+	if pin.VerseKey != "" {
+		verse, err := quran.GetVerseByKey(pin.VerseKey)
+		if err != nil {
+			helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid verseKey", nil)
+			return
+		}
+		if pin.Page != 0 && pin.Page != verse.Page {
+			helpers.ResponseJSON(w, nil, http.StatusBadRequest, "verseKey is not on the given page", nil)
+			return
+		}
+		pin.Page = verse.Page
+	}
+	if pin.Page < 1 || pin.Page > quran.TotalPages {
+		helpers.ResponseJSON(w, nil, http.StatusBadRequest, "invalid page", nil)
+		return
+	}
+
+	if pin.TargetID != 0 {
+		readingTarget, err := store.ReadingTargets.GetByID(pin.TargetID)
+		if err != nil || readingTarget.UserID != user.ID {
+			helpers.ResponseJSON(w, err, http.StatusBadRequest, "Error get reading target", nil)
+			return
+		}
+		if pin.Page < readingTarget.StartPage || pin.Page > readingTarget.EndPage {
+			helpers.ResponseJSON(w, nil, http.StatusBadRequest, "page is more or less than target", nil)
+			return
+		}
+	}
+
+	pin.UserID = user.ID
+	pin.UpdatedAt = time.Now()
+	err = store.Bookmarks.Save(&pin)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error saving bookmark", nil)
+		return
+	}
+
+	bookmark, err := pinnedBookmark(pin)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error get bookmark", nil)
+		return
+	}
+	helpers.ResponseJSON(w, nil, http.StatusOK, "SUCCESS", bookmark)
+}
+
+// UnpinBookmark removes the pinned bookmark, the last position read is used
+// again.
+func UnpinBookmark(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	user, err := getUserByUsername(vars["id"])
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Error get user", nil)
+		return
+	}
+
+	err = store.Bookmarks.Delete(user.ID)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error deleting bookmark", nil)
+		return
+	}
+
+	helpers.ResponseJSON(w, err, http.StatusNoContent, "SUCCESS", nil)
+}
+
+// lastReadBookmark finds the newest first reading in the active targets
+// that still have unread pages. found is false when there is none.
+func lastReadBookmark(userID int) (dto.Bookmark, bool, error) {
+	readingTargets, err := store.ReadingTargets.GetByUserID(userID)
+	if err != nil {
+		return dto.Bookmark{}, false, err
+	}
+	readingProgresses, err := store.ReadingProgress.GetByUserID(userID)
+	if err != nil {
+		return dto.Bookmark{}, false, err
+	}
+
+	targetProgresses := make(map[int][]dto.ReadingProgress)
+	for _, readingProgress := range firstReadings(readingProgresses) {
+		targetProgresses[readingProgress.TargetID] = append(targetProgresses[readingProgress.TargetID], readingProgress)
+	}
+
+	today := localDay(time.Now(), targetLocation(userID))
+	var bookmark dto.Bookmark
+	found := false
+	for _, readingTarget := range readingTargets {
+		if targetStatus(readingTarget, today) != dto.TargetStatusActive {
+			continue
+		}
+		progresses := targetProgresses[readingTarget.ID]
+		if len(progresses) == 0 {
+			continue
+		}
+		coverage := newPageCoverage(progresses)
+		nextPage := nextUnreadPage(readingTarget, coverage, readingTarget.StartPage)
+		if nextPage == 0 {
+			continue
+		}
+
+		// Progress is ordered from oldest to newest
+		last := progresses[len(progresses)-1]
+		if found && !last.ReadAt.After(bookmark.UpdatedAt) {
+			continue
+		}
+		verseKey := last.EndVerse
+		if verseKey == "" {
+			verses, err := quran.GetPageVerses(last.CurrentPage)
+			if err != nil {
+				continue
+			}
+			verseKey = verses[len(verses)-1].Key
+		}
+
+		candidate, err := bookmarkPosition(verseKey)
+		if err != nil {
+			continue
+		}
+		candidate.Source = dto.BookmarkSourceAuto
+		candidate.TargetID = readingTarget.ID
+		candidate.TargetName = readingTarget.Name
+		candidate.NextUnreadPage = nextUnreadPage(readingTarget, coverage, candidate.Page)
+		candidate.UpdatedAt = last.ReadAt
+		bookmark, found = candidate, true
+	}
+	return bookmark, found, nil
+}
+
+// pinnedBookmark describes a pinned bookmark, the target is left out when
+// it has been deleted since.
+func pinnedBookmark(pin dto.BookmarkPin) (dto.Bookmark, error) {
+	verseKey := pin.VerseKey
+	if verseKey == "" {
+		verses, err := quran.GetPageVerses(pin.Page)
+		if err != nil {
+			return dto.Bookmark{}, err
+		}
+		verseKey = verses[0].Key
+	}
+
+	bookmark, err := bookmarkPosition(verseKey)
+	if err != nil {
+		return dto.Bookmark{}, err
+	}
+	bookmark.Source = dto.BookmarkSourceManual
+	bookmark.UpdatedAt = pin.UpdatedAt
+
+	if pin.TargetID == 0 {
+		return bookmark, nil
+	}
+	readingTarget, err := store.ReadingTargets.GetByID(pin.TargetID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return bookmark, nil
+	} else if err != nil {
+		return dto.Bookmark{}, err
+	}
+	progresses, err := getReadingProgressByUserIDTargetID(pin.UserID, readingTarget.ID)
+	if err != nil {
+		return dto.Bookmark{}, err
+	}
+	bookmark.TargetID = readingTarget.ID
+	bookmark.TargetName = readingTarget.Name
+	bookmark.NextUnreadPage = nextUnreadPage(readingTarget, newPageCoverage(progresses), bookmark.Page)
+	return bookmark, nil
+}
+
+// bookmarkPosition fills the page, surah and juz of a verse.
+func bookmarkPosition(verseKey string) (dto.Bookmark, error) {
+	verse, err := quran.GetVerseByKey(verseKey)
+	if err != nil {
+		return dto.Bookmark{}, err
+	}
+	surah, err := quran.GetSurah(verse.Surah)
+	if err != nil {
+		return dto.Bookmark{}, err
+	}
+	return dto.Bookmark{
+		Page:        verse.Page,
+		VerseKey:    verse.Key,
+		SurahNumber: surah.Number,
+		SurahName:   surah.Name,
+		Juz:         verse.Juz,
+	}, nil
+}
+
+// nextUnreadPage returns the first page of the target from page onwards that
+// is not completely read, wrapping around to the start of the target. It is
+// 0 when every page is read.
+func nextUnreadPage(readingTarget dto.ReadingTarget, coverage *pageCoverage, page int) int {
+	if page < readingTarget.StartPage || page > readingTarget.EndPage {
+		page = readingTarget.StartPage
+	}
+	for offset := 0; offset <= readingTarget.EndPage-readingTarget.StartPage; offset++ {
+		candidate := page + offset
+		if candidate > readingTarget.EndPage {
+			candidate -= readingTarget.EndPage - readingTarget.StartPage + 1
+		}
+		if !coverage.Complete(candidate) {
+			return candidate
+		}
+	}
+	return 0
+}
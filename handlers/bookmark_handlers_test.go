@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/dto"
+)
+
+func TestGetBookmarkSkipsInactiveTargets(t *testing.T) {
+	newTestStore(t)
+	user := newTestUser(t, "fulan", "")
+	active := newTestTarget(t, dto.ReadingTarget{UserID: user.ID, Name: "Aktif", StartDate: "2026-01-01", EndDate: "2099-12-31", StartPage: 1, EndPage: 20, Pages: 20})
+	paused := newTestTarget(t, dto.ReadingTarget{UserID: user.ID, Name: "Jeda", StartDate: "2026-01-01", EndDate: "2099-12-31", StartPage: 1, EndPage: 20, Pages: 20, Status: dto.TargetStatusPaused})
+	expired := newTestTarget(t, dto.ReadingTarget{UserID: user.ID, Name: "Lewat", StartDate: "2026-01-01", EndDate: "2026-01-31", StartPage: 1, EndPage: 20, Pages: 20})
+
+	now := time.Now()
+	for i, readingTarget := range []dto.ReadingTarget{active, paused, expired} {
+		readingProgress := dto.ReadingProgress{UserID: user.ID, TargetID: readingTarget.ID, CurrentPage: 3, ReadAt: now.Add(time.Duration(i-3) * time.Hour)}
+		if err := store.ReadingProgress.Create(&readingProgress); err != nil {
+			t.Fatalf("create progress: %v", err)
+		}
+	}
+
+	var bookmark dto.Bookmark
+	response := serve(t, GetBookmark, http.MethodGet, map[string]string{"id": user.Username}, nil, &bookmark)
+	if response.Code != http.StatusOK {
+		t.Fatalf("get bookmark: status = %d (%v)", response.Code, response.Message)
+	}
+	if bookmark.TargetID != active.ID || bookmark.NextUnreadPage != 4 {
+		t.Errorf("bookmark = target %d next page %d, want target %d next page 4", bookmark.TargetID, bookmark.NextUnreadPage, active.ID)
+	}
+}
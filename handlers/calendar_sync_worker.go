@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	externalDto "github.com/daffashafwan/tadarus-yuk/external/dto"
+	"github.com/daffashafwan/tadarus-yuk/internal/dto"
+	"github.com/daffashafwan/tadarus-yuk/internal/storage"
+	"google.golang.org/api/googleapi"
+)
+
+const (
+	calendarSyncBatchSize  = 20
+	calendarSyncMaxBackoff = time.Hour
+	// calendarSyncLease is how long a claimed entry stays hidden from other
+	// workers, long enough to push a whole batch.
+	calendarSyncLease = 5 * time.Minute
+)
+
+var (
+	calendarSyncInterval    = 10 * time.Second
+	calendarSyncMaxAttempts = 8
+)
+
+// InitCalendarSync reads the calendar sync worker settings from the environment.
+func InitCalendarSync() {
+	if val, err := strconv.Atoi(os.Getenv("CALENDAR_SYNC_INTERVAL")); err == nil && val > 0 {
+		calendarSyncInterval = time.Duration(val) * time.Millisecond
+	}
+	if val, err := strconv.Atoi(os.Getenv("CALENDAR_SYNC_MAX_ATTEMPTS")); err == nil && val > 0 {
+		calendarSyncMaxAttempts = val
+	}
+}
+
+// RunCalendarSyncWorker pushes queued reading target changes to Google Calendar
+// until the context is cancelled.
+func RunCalendarSyncWorker(ctx context.Context) {
+	ticker := time.NewTicker(calendarSyncInterval)
+	defer ticker.Stop()
+
+	for {
+		processCalendarOutbox()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func processCalendarOutbox() {
+	entries, err := store.CalendarOutbox.Claim(time.Now(), calendarSyncLease, calendarSyncBatchSize)
+	if err != nil {
+		log.Printf("[calendarSync] error get outbox : %v", err.Error())
+		return
+	}
+
+	for _, entry := range entries {
+		googleCalendarID, syncStatus, err := applyCalendarOutbox(entry)
+		if err != nil {
+			failCalendarOutbox(entry, err)
+			continue
+		}
+
+		entry.Status = dto.CalendarOutboxDone
+		entry.LastError = ""
+		// The event ID is saved with the entry so a later EDIT does not add
+		// the event again.
+		var calendarSync *dto.CalendarSync
+		if syncStatus != "" {
+			now := time.Now()
+			calendarSync = &dto.CalendarSync{Status: syncStatus, SyncedAt: &now}
+		}
+		if err := store.CalendarOutbox.Complete(entry, googleCalendarID, calendarSync); err != nil {
+			log.Printf("[calendarSync] error update outbox %d : %v", entry.ID, err.Error())
+		}
+	}
+}
+
+// applyCalendarOutbox pushes one entry and returns the event ID and sync
+// status to record on the target, the status is empty when the target no
+// longer exists.
+func applyCalendarOutbox(entry dto.CalendarOutbox) (string, string, error) {
+	user, err := getUserByIDWithoutEncrypt(entry.UserID)
+	if err != nil {
+		return "", "", err
+	}
+
+	if entry.Operation == "DELETE" {
+		if entry.GoogleCalendarID == "" || user.GoogleToken == "" {
+			return "", "", nil
+		}
+		_, err := pushCalendarEvent(user.GoogleToken, externalDto.CalendarEvent{
+			GoogleCalendarID: entry.GoogleCalendarID,
+			StartDate:        "2006-01-02",
+			EndDate:          "2006-01-02",
+			Type:             "DELETE",
+		})
+		if isGoogleEventGone(err) {
+			return "", "", nil
+		}
+		return "", "", err
+	}
+
+	readingTarget, err := store.ReadingTargets.GetByID(entry.TargetID)
+	if err == storage.ErrNotFound {
+		return "", "", nil
+	} else if err != nil {
+		return "", "", err
+	}
+
+	if user.GoogleToken == "" {
+		return "", dto.CalendarSyncSkipped, nil
+	}
+
+	// A retried ADD may already have an event, and an EDIT queued before the
+	// ADD went through has none yet.
+	eventType := "EDIT"
+	if readingTarget.GoogleCalendarID == "" {
+		eventType = "ADD"
+	}
+
+	event, err := pushCalendarEvent(user.GoogleToken, calendarEventFromTarget(readingTarget, eventType))
+	if err != nil {
+		return "", "", err
+	}
+
+	return event.Id, dto.CalendarSyncSynced, nil
+}
+
+func failCalendarOutbox(entry dto.CalendarOutbox, err error) {
+	log.Printf("[calendarSync] error push outbox %d (%s target %d) : %v", entry.ID, entry.Operation, entry.TargetID, err.Error())
+
+	entry.Attempts++
+	entry.LastError = err.Error()
+	syncStatus := dto.CalendarSyncRetrying
+	if entry.Attempts >= calendarSyncMaxAttempts {
+		entry.Status = dto.CalendarSyncFailed
+		syncStatus = dto.CalendarSyncFailed
+	} else {
+		entry.NextAttemptAt = time.Now().Add(calendarSyncBackoff(entry.Attempts))
+	}
+
+	if err := store.CalendarOutbox.Update(entry); err != nil {
+		log.Printf("[calendarSync] error update outbox %d : %v", entry.ID, err.Error())
+	}
+	if entry.Operation != "DELETE" {
+		setCalendarSync(entry.TargetID, "", dto.CalendarSync{Status: syncStatus, Error: entry.LastError})
+	}
+}
+
+// calendarSyncBackoff doubles the worker interval on every failed attempt.
+func calendarSyncBackoff(attempts int) time.Duration {
+	backoff := calendarSyncInterval
+	for i := 1; i < attempts && backoff < calendarSyncMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > calendarSyncMaxBackoff {
+		backoff = calendarSyncMaxBackoff
+	}
+	return backoff
+}
+
+func setCalendarSync(targetID int, googleCalendarID string, calendarSync dto.CalendarSync) {
+	if err := store.ReadingTargets.UpdateCalendarSync(targetID, googleCalendarID, calendarSync); err != nil {
+		log.Printf("[calendarSync] error update target %d : %v", targetID, err.Error())
+	}
+}
+
+func calendarEventFromTarget(readingTarget dto.ReadingTarget, eventType string) externalDto.CalendarEvent {
+	description := "Membaca Halaman " + strconv.Itoa(readingTarget.StartPage) + " sampai " + strconv.Itoa(readingTarget.EndPage)
+	if readingTarget.Scope != nil {
+		description = "Membaca " + dto.TargetLabel(readingTarget.Scope, readingTarget.StartPage, readingTarget.EndPage)
+	}
+	return externalDto.CalendarEvent{
+		GoogleCalendarID: readingTarget.GoogleCalendarID,
+		EventName:        readingTarget.Name,
+		EventDescription: description,
+		StartDate:        dateOnly(readingTarget.StartDate),
+		EndDate:          dateOnly(readingTarget.EndDate),
+		Recurrence:       readingTarget.Recurrence,
+		Type:             eventType,
+	}
+}
+
+// dateOnly trims the time part the database driver adds to DATE columns.
+func dateOnly(date string) string {
+	if len(date) > len("2006-01-02") {
+		return date[:len("2006-01-02")]
+	}
+	return date
+}
+
+func isGoogleEventGone(err error) bool {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusNotFound || apiErr.Code == http.StatusGone
+	}
+	return false
+}
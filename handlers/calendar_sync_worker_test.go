@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/dto"
+)
+
+func TestCalendarOutboxClaim(t *testing.T) {
+	newTestStore(t)
+	user := newTestUser(t, "fulan", "")
+	newTestTarget(t, dto.ReadingTarget{UserID: user.ID, StartDate: "2026-10-01", EndDate: "2026-10-30", StartPage: 1, EndPage: 20, Pages: 20})
+
+	now := time.Now()
+	entries, err := store.CalendarOutbox.Claim(now, time.Minute, calendarSyncBatchSize)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("first claim = %d entries, %v, want 1", len(entries), err)
+	}
+
+	entries, err = store.CalendarOutbox.Claim(now, time.Minute, calendarSyncBatchSize)
+	if err != nil || len(entries) != 0 {
+		t.Errorf("claim during the lease = %d entries, %v, want 0", len(entries), err)
+	}
+
+	entries, err = store.CalendarOutbox.Claim(now.Add(2*time.Minute), time.Minute, calendarSyncBatchSize)
+	if err != nil || len(entries) != 1 {
+		t.Errorf("claim after the lease = %d entries, %v, want 1", len(entries), err)
+	}
+}
+
+func TestProcessCalendarOutboxWithoutGoogle(t *testing.T) {
+	newTestStore(t)
+	user := newTestUser(t, "fulan", "")
+	readingTarget := newTestTarget(t, dto.ReadingTarget{UserID: user.ID, StartDate: "2026-10-01", EndDate: "2026-10-30", StartPage: 1, EndPage: 20, Pages: 20})
+
+	processCalendarOutbox()
+
+	stored, err := store.ReadingTargets.GetByID(readingTarget.ID)
+	if err != nil {
+		t.Fatalf("get target: %v", err)
+	}
+	if stored.CalendarSync.Status != dto.CalendarSyncSkipped {
+		t.Errorf("sync status = %q, want %q", stored.CalendarSync.Status, dto.CalendarSyncSkipped)
+	}
+
+	entries, err := store.CalendarOutbox.Claim(time.Now().Add(time.Hour), time.Minute, calendarSyncBatchSize)
+	if err != nil || len(entries) != 0 {
+		t.Errorf("claim after processing = %d entries, %v, want 0", len(entries), err)
+	}
+}
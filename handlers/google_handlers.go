@@ -69,7 +69,7 @@ func GoogleCallback(w http.ResponseWriter, r *http.Request) {
 
 	userByEmail, _ := getUserByEmail(user.Email)
 	if userByEmail.Email == "" {
-		err = createUser(dto.User{
+		err = createUser(&dto.User{
 			Username:    user.ID,
 			Email:       user.Email,
 			GoogleToken: token.AccessToken,
@@ -126,6 +126,15 @@ func getClientWithAccessToken(accessToken string) *http.Client {
 	return config.Client(context.Background(), token)
 }
 
+// recurrenceDescriptions names the recurrence of a target in its event. The
+// event repeats daily over one instance, the scheduler creates the next
+// instance with its own event.
+var recurrenceDescriptions = map[string]string{
+	dto.RecurrenceWeekly:  "Berulang setiap minggu",
+	dto.RecurrenceMonthly: "Berulang setiap bulan",
+	dto.RecurrenceRamadan: "Berulang setiap Ramadan",
+}
+
 func pushCalendarEvent(accessToken string, calendarEvent externalDto.CalendarEvent) (*calendar.Event, error) {
 	// Create a new Calendar service
 	var eventCreated *calendar.Event
@@ -155,9 +164,14 @@ func pushCalendarEvent(accessToken string, calendarEvent externalDto.CalendarEve
 
 	daysLength := int(endDate.Sub(startDate).Hours() / 24) + 1
 
+	description := calendarEvent.EventDescription
+	if recurrence, ok := recurrenceDescriptions[calendarEvent.Recurrence]; ok {
+		description += "\n" + recurrence
+	}
+
 	event := &calendar.Event{
 		Summary:     calendarEvent.EventName + " [Update Progress di App Tadaroosh]",
-		Description: calendarEvent.EventDescription,
+		Description: description,
 		Start: &calendar.EventDateTime{
 			DateTime: startDateFormat,
 			TimeZone: "Asia/Jakarta",
@@ -0,0 +1,606 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/dto"
+	"github.com/daffashafwan/tadarus-yuk/internal/helpers"
+	"github.com/daffashafwan/tadarus-yuk/internal/quran"
+	"github.com/daffashafwan/tadarus-yuk/internal/storage"
+	"github.com/gorilla/mux"
+)
+
+// CreateGroup creates a study group owned by the user.
+func CreateGroup(w http.ResponseWriter, r *http.Request) {
+	var groupRequest dto.GroupRequest
+	err := json.NewDecoder(r.Body).Decode(&groupRequest)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	vars := mux.Vars(r)
+	user, err := getUserByUsername(vars["id"])
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Error get user", nil)
+		return
+	}
+
+	group := dto.Group{Name: strings.TrimSpace(groupRequest.Name), OwnerID: user.ID}
+	if group.Name == "" {
+		helpers.ResponseJSON(w, nil, http.StatusBadRequest, "name is required", nil)
+		return
+	}
+
+	err = store.Groups.Create(&group)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error creating group", nil)
+		return
+	}
+
+	group, err = store.Groups.GetByID(group.ID)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching group", nil)
+		return
+	}
+	helpers.ResponseJSON(w, err, http.StatusCreated, "SUCCESS", group)
+}
+
+// GetGroups lists the groups the user is a member of.
+func GetGroups(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	user, err := getUserByUsername(vars["id"])
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Error get user", nil)
+		return
+	}
+
+	groups, err := store.Groups.GetByUserID(user.ID)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching groups", nil)
+		return
+	}
+
+	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", groups)
+}
+
+func GetGroupByID(w http.ResponseWriter, r *http.Request) {
+	_, group, status, err := getUserGroup(r)
+	if err != nil {
+		helpers.ResponseJSON(w, err, status, "Error fetching group", nil)
+		return
+	}
+
+	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", group)
+}
+
+// DeleteGroup removes a group and its targets. The members keep their
+// personal reading targets and the progress logged on them.
+func DeleteGroup(w http.ResponseWriter, r *http.Request) {
+	user, group, status, err := getUserGroup(r)
+	if err != nil {
+		helpers.ResponseJSON(w, err, status, "Error fetching group", nil)
+		return
+	}
+	if group.OwnerID != user.ID {
+		helpers.ResponseJSON(w, nil, http.StatusForbidden, "only the owner can delete the group", nil)
+		return
+	}
+
+	err = store.Groups.Delete(group.ID)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error deleting group", nil)
+		return
+	}
+
+	helpers.ResponseJSON(w, err, http.StatusNoContent, "SUCCESS", nil)
+}
+
+// AddGroupMember lets the owner add a user to the group by username.
+func AddGroupMember(w http.ResponseWriter, r *http.Request) {
+	var memberRequest dto.GroupMemberRequest
+	err := json.NewDecoder(r.Body).Decode(&memberRequest)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	user, group, status, err := getUserGroup(r)
+	if err != nil {
+		helpers.ResponseJSON(w, err, status, "Error fetching group", nil)
+		return
+	}
+	if group.OwnerID != user.ID {
+		helpers.ResponseJSON(w, nil, http.StatusForbidden, "only the owner can add members", nil)
+		return
+	}
+
+	member, err := getUserByUsername(memberRequest.Username)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Error get user", nil)
+		return
+	}
+
+	err = store.Groups.AddMember(group.ID, member.ID)
+	if err == storage.ErrConflict {
+		helpers.ResponseJSON(w, err, http.StatusConflict, memberRequest.Username+" is already a member", nil)
+		return
+	} else if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error adding group member", nil)
+		return
+	}
+
+	group, err = store.Groups.GetByID(group.ID)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching group", nil)
+		return
+	}
+	helpers.ResponseJSON(w, err, http.StatusCreated, "SUCCESS", group)
+}
+
+// RemoveGroupMember lets the owner remove a member, or a member leave. The
+// owner can not leave, the assignments of a removed member can be
+// reassigned.
+func RemoveGroupMember(w http.ResponseWriter, r *http.Request) {
+	user, group, status, err := getUserGroup(r)
+	if err != nil {
+		helpers.ResponseJSON(w, err, status, "Error fetching group", nil)
+		return
+	}
+
+	vars := mux.Vars(r)
+	member, ok := groupMember(group, vars["username"])
+	if !ok {
+		helpers.ResponseJSON(w, nil, http.StatusNotFound, vars["username"]+" is not a member", nil)
+		return
+	}
+	if group.OwnerID != user.ID && member.UserID != user.ID {
+		helpers.ResponseJSON(w, nil, http.StatusForbidden, "only the owner can remove other members", nil)
+		return
+	}
+	if member.UserID == group.OwnerID {
+		helpers.ResponseJSON(w, nil, http.StatusBadRequest, "the owner can not leave the group", nil)
+		return
+	}
+
+	err = store.Groups.RemoveMember(group.ID, member.UserID)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error removing group member", nil)
+		return
+	}
+
+	helpers.ResponseJSON(w, err, http.StatusNoContent, "SUCCESS", nil)
+}
+
+// CreateGroupTarget lets the owner start a group khatam. Every assignment
+// becomes a personal reading target of its member.
+func CreateGroupTarget(w http.ResponseWriter, r *http.Request) {
+	var targetRequest dto.GroupTargetRequest
+	err := json.NewDecoder(r.Body).Decode(&targetRequest)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	user, group, status, err := getUserGroup(r)
+	if err != nil {
+		helpers.ResponseJSON(w, err, status, "Error fetching group", nil)
+		return
+	}
+	if group.OwnerID != user.ID {
+		helpers.ResponseJSON(w, nil, http.StatusForbidden, "only the owner can create group targets", nil)
+		return
+	}
+
+	groupTarget := dto.GroupTarget{
+		GroupID:   group.ID,
+		Name:      strings.TrimSpace(targetRequest.Name),
+		StartDate: targetRequest.StartDate,
+		EndDate:   targetRequest.EndDate,
+		StartPage: targetRequest.StartPage,
+		EndPage:   targetRequest.EndPage,
+	}
+	if groupTarget.StartPage == 0 && groupTarget.EndPage == 0 {
+		groupTarget.StartPage, groupTarget.EndPage = 1, quran.TotalPages
+	}
+	if groupTarget.Name == "" {
+		helpers.ResponseJSON(w, nil, http.StatusBadRequest, "name is required", nil)
+		return
+	}
+	if !isValidDateRange(groupTarget.StartDate, groupTarget.EndDate) {
+		helpers.ResponseJSON(w, nil, http.StatusBadRequest, "invalid date or date range", nil)
+		return
+	}
+	if groupTarget.StartPage < 1 || groupTarget.EndPage > quran.TotalPages || groupTarget.EndPage < groupTarget.StartPage {
+		helpers.ResponseJSON(w, nil, http.StatusBadRequest, "invalid page range", nil)
+		return
+	}
+
+	groupTarget.Assignments, err = buildGroupAssignments(group, groupTarget, targetRequest)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid assignments", nil)
+		return
+	}
+
+	// The calendar events are pushed by the calendar sync worker
+	err = store.Groups.CreateTarget(&groupTarget)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error creating group target", nil)
+		return
+	}
+
+	groupTarget, err = store.Groups.GetTarget(groupTarget.ID)
+	if err == nil {
+		err = describeGroupTarget(&groupTarget)
+	}
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching group target", nil)
+		return
+	}
+	helpers.ResponseJSON(w, err, http.StatusCreated, "SUCCESS", groupTarget)
+}
+
+// GetGroupTargets lists the targets of a group with the combined progress.
+func GetGroupTargets(w http.ResponseWriter, r *http.Request) {
+	_, group, status, err := getUserGroup(r)
+	if err != nil {
+		helpers.ResponseJSON(w, err, status, "Error fetching group", nil)
+		return
+	}
+
+	groupTargets, err := store.Groups.GetTargets(group.ID)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching group targets", nil)
+		return
+	}
+	for i := range groupTargets {
+		if err := describeGroupTarget(&groupTargets[i]); err != nil {
+			helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error getting reading progress in group target", nil)
+			return
+		}
+	}
+
+	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", groupTargets)
+}
+
+func GetGroupTargetByID(w http.ResponseWriter, r *http.Request) {
+	_, group, status, err := getUserGroup(r)
+	if err != nil {
+		helpers.ResponseJSON(w, err, status, "Error fetching group", nil)
+		return
+	}
+
+	groupTarget, status, err := getGroupTarget(group, mux.Vars(r)["gtid"])
+	if err != nil {
+		helpers.ResponseJSON(w, err, status, "Error fetching group target", nil)
+		return
+	}
+	if err := describeGroupTarget(&groupTarget); err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error getting reading progress in group target", nil)
+		return
+	}
+
+	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", groupTarget)
+}
+
+// ReassignGroupAssignment lets the owner give the pages of an assignment
+// that are not read yet to another member, one assignment per run of
+// consecutive pages. The previous member keeps the personal target and the
+// pages read on it still count for the group.
+func ReassignGroupAssignment(w http.ResponseWriter, r *http.Request) {
+	var reassignRequest dto.GroupReassignRequest
+	err := json.NewDecoder(r.Body).Decode(&reassignRequest)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	user, group, status, err := getUserGroup(r)
+	if err != nil {
+		helpers.ResponseJSON(w, err, status, "Error fetching group", nil)
+		return
+	}
+	if group.OwnerID != user.ID {
+		helpers.ResponseJSON(w, nil, http.StatusForbidden, "only the owner can reassign", nil)
+		return
+	}
+
+	vars := mux.Vars(r)
+	groupTarget, status, err := getGroupTarget(group, vars["gtid"])
+	if err != nil {
+		helpers.ResponseJSON(w, err, status, "Error fetching group target", nil)
+		return
+	}
+
+	var assignment dto.GroupAssignment
+	for _, a := range groupTarget.Assignments {
+		if strconv.Itoa(a.ID) == vars["aid"] && a.Status == dto.GroupAssignmentActive {
+			assignment = a
+		}
+	}
+	if assignment.ID == 0 {
+		helpers.ResponseJSON(w, nil, http.StatusNotFound, "Assignment with ID "+vars["aid"]+" not found", nil)
+		return
+	}
+
+	member, ok := groupMember(group, reassignRequest.Username)
+	if !ok {
+		helpers.ResponseJSON(w, nil, http.StatusBadRequest, reassignRequest.Username+" is not a member", nil)
+		return
+	}
+	if member.UserID == assignment.UserID {
+		helpers.ResponseJSON(w, nil, http.StatusBadRequest, "the assignment already belongs to "+member.Username, nil)
+		return
+	}
+
+	progresses, err := getReadingProgressByUserIDTargetID(assignment.UserID, assignment.TargetID)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error get reading progress", nil)
+		return
+	}
+	coverage := newPageCoverage(progresses)
+
+	var assignments []*dto.GroupAssignment
+	for page := assignment.StartPage; page <= assignment.EndPage; page++ {
+		if coverage.Complete(page) {
+			continue
+		}
+		last := len(assignments) - 1
+		if last >= 0 && assignments[last].EndPage == page-1 {
+			assignments[last].EndPage = page
+			continue
+		}
+		assignments = append(assignments, &dto.GroupAssignment{UserID: member.UserID, StartPage: page, EndPage: page})
+	}
+	if len(assignments) == 0 {
+		helpers.ResponseJSON(w, nil, http.StatusConflict, "every page of the assignment is already read", nil)
+		return
+	}
+
+	err = store.Groups.Reassign(groupTarget, assignment.ID, assignments)
+	if err == storage.ErrNotFound {
+		helpers.ResponseJSON(w, err, http.StatusConflict, "the assignment was reassigned already", nil)
+		return
+	} else if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error reassigning", nil)
+		return
+	}
+
+	groupTarget, err = store.Groups.GetTarget(groupTarget.ID)
+	if err == nil {
+		err = describeGroupTarget(&groupTarget)
+	}
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching group target", nil)
+		return
+	}
+	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", groupTarget)
+}
+
+// getUserGroup loads the user in the id path variable and the group in gid,
+// a group the user is not a member of is not found.
+func getUserGroup(r *http.Request) (dto.User, dto.Group, int, error) {
+	vars := mux.Vars(r)
+	user, err := getUserByUsername(vars["id"])
+	if err != nil {
+		return dto.User{}, dto.Group{}, http.StatusBadRequest, err
+	}
+
+	notFoundErr := fmt.Errorf("Group with ID %s not found", vars["gid"])
+	id, err := strconv.Atoi(vars["gid"])
+	if err != nil {
+		return dto.User{}, dto.Group{}, http.StatusBadRequest, notFoundErr
+	}
+	group, err := store.Groups.GetByID(id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return dto.User{}, dto.Group{}, http.StatusNotFound, notFoundErr
+	} else if err != nil {
+		return dto.User{}, dto.Group{}, http.StatusInternalServerError, err
+	}
+	if _, ok := groupMember(group, user.Username); !ok {
+		return dto.User{}, dto.Group{}, http.StatusNotFound, notFoundErr
+	}
+	return user, group, http.StatusOK, nil
+}
+
+func getGroupTarget(group dto.Group, groupTargetID string) (dto.GroupTarget, int, error) {
+	notFoundErr := fmt.Errorf("Group target with ID %s not found", groupTargetID)
+	id, err := strconv.Atoi(groupTargetID)
+	if err != nil {
+		return dto.GroupTarget{}, http.StatusBadRequest, notFoundErr
+	}
+	groupTarget, err := store.Groups.GetTarget(id)
+	if errors.Is(err, storage.ErrNotFound) || (err == nil && groupTarget.GroupID != group.ID) {
+		return dto.GroupTarget{}, http.StatusNotFound, notFoundErr
+	} else if err != nil {
+		return dto.GroupTarget{}, http.StatusInternalServerError, err
+	}
+	return groupTarget, http.StatusOK, nil
+}
+
+func groupMember(group dto.Group, username string) (dto.GroupMember, bool) {
+	for _, member := range group.Members {
+		if member.Username == username {
+			return member, true
+		}
+	}
+	return dto.GroupMember{}, false
+}
+
+// buildGroupAssignments splits the target between the members for the juz
+// and pages modes, or checks the manual assignments.
+func buildGroupAssignments(group dto.Group, groupTarget dto.GroupTarget, targetRequest dto.GroupTargetRequest) ([]dto.GroupAssignment, error) {
+	mode := targetRequest.Mode
+	if mode == "" {
+		mode = dto.GroupAssignModeJuz
+	}
+
+	var assignments []dto.GroupAssignment
+	switch mode {
+	case dto.GroupAssignModeJuz:
+		firstJuz, lastJuz, ok := juzSpan(groupTarget.StartPage, groupTarget.EndPage)
+		if !ok {
+			return nil, errors.New("the page range does not follow juz boundaries, use mode pages")
+		}
+		juzCount := lastJuz - firstJuz + 1
+		members := len(group.Members)
+		if members > juzCount {
+			members = juzCount
+		}
+		for i := 0; i < members; i++ {
+			from := firstJuz
+			if i > 0 {
+				from += splitPages(juzCount, members, i-1)
+			}
+			to := firstJuz + splitPages(juzCount, members, i) - 1
+			startPage, _, _ := groupJuzPages(from)
+			_, endPage, _ := groupJuzPages(to)
+			assignments = append(assignments, dto.GroupAssignment{UserID: group.Members[i].UserID, StartPage: startPage, EndPage: endPage})
+		}
+	case dto.GroupAssignModePages:
+		pages := groupTarget.EndPage - groupTarget.StartPage + 1
+		members := len(group.Members)
+		if members > pages {
+			members = pages
+		}
+		for i := 0; i < members; i++ {
+			from := groupTarget.StartPage
+			if i > 0 {
+				from += splitPages(pages, members, i-1)
+			}
+			to := groupTarget.StartPage + splitPages(pages, members, i) - 1
+			assignments = append(assignments, dto.GroupAssignment{UserID: group.Members[i].UserID, StartPage: from, EndPage: to})
+		}
+	case dto.GroupAssignModeManual:
+		if len(targetRequest.Assignments) == 0 {
+			return nil, errors.New("assignments are required in manual mode")
+		}
+		assigned := make(map[int]bool)
+		for _, assignmentRequest := range targetRequest.Assignments {
+			member, ok := groupMember(group, assignmentRequest.Username)
+			if !ok {
+				return nil, fmt.Errorf("%s is not a member", assignmentRequest.Username)
+			}
+			startPage, endPage := assignmentRequest.StartPage, assignmentRequest.EndPage
+			if assignmentRequest.Juz != 0 {
+				var err error
+				startPage, endPage, err = groupJuzPages(assignmentRequest.Juz)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if startPage < groupTarget.StartPage || endPage > groupTarget.EndPage || endPage < startPage {
+				return nil, fmt.Errorf("pages %d - %d of %s are outside the target", startPage, endPage, member.Username)
+			}
+			for page := startPage; page <= endPage; page++ {
+				if assigned[page] {
+					return nil, fmt.Errorf("page %d is assigned twice", page)
+				}
+				assigned[page] = true
+			}
+			assignments = append(assignments, dto.GroupAssignment{UserID: member.UserID, StartPage: startPage, EndPage: endPage})
+		}
+	default:
+		return nil, fmt.Errorf("mode must be %s, %s or %s", dto.GroupAssignModeJuz, dto.GroupAssignModePages, dto.GroupAssignModeManual)
+	}
+	return assignments, nil
+}
+
+// describeGroupTarget computes the progress of every assignment and of the
+// whole target. A page counts for the group with the largest share read in
+// any assignment holding it, reassigned ones included.
+func describeGroupTarget(groupTarget *dto.GroupTarget) error {
+	shares := make(map[int]float64)
+	completedAt := make(map[int]time.Time)
+	for i := range groupTarget.Assignments {
+		assignment := &groupTarget.Assignments[i]
+		progresses, err := getReadingProgressByUserIDTargetID(assignment.UserID, assignment.TargetID)
+		if err != nil {
+			return err
+		}
+		coverage := newPageCoverage(progresses)
+
+		pages := float64(assignment.EndPage - assignment.StartPage + 1)
+		assignment.PagesRead = roundOneDecimal(coverage.PagesRead(assignment.StartPage, assignment.EndPage))
+		assignment.Progress = math.Min(coverage.PagesRead(assignment.StartPage, assignment.EndPage)/pages*100, 100)
+		assignment.Progress = float64(int(assignment.Progress*10)) / 10
+		assignment.FirstJuz, assignment.LastJuz, _ = juzSpan(assignment.StartPage, assignment.EndPage)
+
+		for page := assignment.StartPage; page <= assignment.EndPage; page++ {
+			shares[page] = math.Max(shares[page], coverage.Share(page))
+			if pageCompletedAt, ok := coverage.CompletedAt(page, page); ok {
+				if current, done := completedAt[page]; !done || pageCompletedAt.Before(current) {
+					completedAt[page] = pageCompletedAt
+				}
+			}
+		}
+	}
+
+	var pagesRead float64
+	var lastCompletedAt time.Time
+	groupTarget.UnassignedPages = 0
+	groupTarget.CompletedAt = nil
+	complete := true
+	for page := groupTarget.StartPage; page <= groupTarget.EndPage; page++ {
+		if _, ok := shares[page]; !ok {
+			groupTarget.UnassignedPages++
+		}
+		pagesRead += shares[page]
+		pageCompletedAt, ok := completedAt[page]
+		if !ok {
+			complete = false
+		} else if pageCompletedAt.After(lastCompletedAt) {
+			lastCompletedAt = pageCompletedAt
+		}
+	}
+	if complete {
+		groupTarget.CompletedAt = &lastCompletedAt
+	}
+
+	pages := float64(groupTarget.EndPage - groupTarget.StartPage + 1)
+	groupTarget.PagesRead = roundOneDecimal(pagesRead)
+	groupTarget.Progress = math.Min(pagesRead/pages*100, 100)
+	groupTarget.Progress = float64(int(groupTarget.Progress*10)) / 10
+	return nil
+}
+
+// groupJuzPages returns the pages of a juz for an assignment. A page shared
+// with the previous juz is left to it, so assigned juz never overlap.
+func groupJuzPages(juz int) (int, int, error) {
+	firstPage, lastPage, err := quran.GetJuzPages(juz)
+	if err != nil {
+		return 0, 0, err
+	}
+	if juz > 1 {
+		if _, previousLastPage, _ := quran.GetJuzPages(juz - 1); previousLastPage == firstPage {
+			firstPage++
+		}
+	}
+	return firstPage, lastPage, nil
+}
+
+// juzSpan returns the first and last juz of a page range, ok is false when
+// the range does not start and end on juz boundaries.
+func juzSpan(startPage, endPage int) (int, int, bool) {
+	firstJuz, lastJuz := 0, 0
+	for juz := 1; juz <= quran.TotalJuz; juz++ {
+		juzStartPage, juzEndPage, _ := groupJuzPages(juz)
+		if juzStartPage == startPage {
+			firstJuz = juz
+		}
+		if juzEndPage == endPage {
+			lastJuz = juz
+		}
+	}
+	if firstJuz == 0 || lastJuz < firstJuz {
+		return 0, 0, false
+	}
+	return firstJuz, lastJuz, true
+}
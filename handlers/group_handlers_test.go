@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/dto"
+)
+
+func TestReassignGroupAssignment(t *testing.T) {
+	newTestStore(t)
+	owner := newTestUser(t, "owner", "")
+	member := newTestUser(t, "member", "")
+	group := dto.Group{Name: "Halaqah", OwnerID: owner.ID}
+	if err := store.Groups.Create(&group); err != nil {
+		t.Fatalf("create group: %v", err)
+	}
+	if err := store.Groups.AddMember(group.ID, member.ID); err != nil {
+		t.Fatalf("add member: %v", err)
+	}
+	groupVars := map[string]string{"id": owner.Username, "gid": strconv.Itoa(group.ID)}
+
+	var groupTarget dto.GroupTarget
+	targetRequest := dto.GroupTargetRequest{Name: "Khatam", StartDate: "2026-10-01", EndDate: "2026-10-30", StartPage: 1, EndPage: 20, Mode: dto.GroupAssignModePages}
+	response := serve(t, CreateGroupTarget, http.MethodPost, groupVars, targetRequest, &groupTarget)
+	if response.Code != http.StatusCreated {
+		t.Fatalf("create group target: status = %d (%v)", response.Code, response.Message)
+	}
+	ownerAssignment := groupTarget.Assignments[0]
+	if ownerAssignment.UserID != owner.ID || ownerAssignment.StartPage != 1 || ownerAssignment.EndPage != 10 {
+		t.Fatalf("owner assignment = %+v, want pages 1 - 10", ownerAssignment)
+	}
+
+	progressVars := map[string]string{"id": owner.Username, "tid": strconv.Itoa(ownerAssignment.TargetID)}
+	serve(t, CreateReadingProgress, http.MethodPost, progressVars, dto.ReadingProgress{CurrentPage: 1}, nil)
+
+	reassignVars := map[string]string{"id": owner.Username, "gid": groupVars["gid"], "gtid": strconv.Itoa(groupTarget.ID), "aid": strconv.Itoa(ownerAssignment.ID)}
+	response = serve(t, ReassignGroupAssignment, http.MethodPost, reassignVars, dto.GroupReassignRequest{Username: member.Username}, &groupTarget)
+	if response.Code != http.StatusOK {
+		t.Fatalf("reassign: status = %d (%v)", response.Code, response.Message)
+	}
+
+	var moved *dto.GroupAssignment
+	for i, assignment := range groupTarget.Assignments {
+		if assignment.Status == dto.GroupAssignmentActive && assignment.UserID == member.ID && assignment.StartPage == 2 {
+			moved = &groupTarget.Assignments[i]
+		}
+	}
+	if moved == nil || moved.EndPage != 10 {
+		t.Fatalf("assignments = %+v, want pages 2 - 10 given to the member", groupTarget.Assignments)
+	}
+
+	oldTarget, err := store.ReadingTargets.GetByID(ownerAssignment.TargetID)
+	if err != nil {
+		t.Fatalf("get old target: %v", err)
+	}
+	if oldTarget.Status != dto.TargetStatusReassigned {
+		t.Errorf("old target status = %q, want %q", oldTarget.Status, dto.TargetStatusReassigned)
+	}
+
+	response = serve(t, ReassignGroupAssignment, http.MethodPost, reassignVars, dto.GroupReassignRequest{Username: member.Username}, nil)
+	if response.Code != http.StatusNotFound {
+		t.Errorf("reassigning twice: status = %d, want %d", response.Code, http.StatusNotFound)
+	}
+}
@@ -1,6 +1,17 @@
 package handlers
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/storage"
+)
+
+var store storage.Store
+
+// InitStore sets the storage used by every handler.
+func InitStore(s storage.Store) {
+	store = s
+}
 
 // HomeHandler handles requests to the home endpoint.
 func Home(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/dto"
+	"github.com/daffashafwan/tadarus-yuk/internal/storage"
+	"github.com/gorilla/mux"
+)
+
+// testResponse is dto.Response with the data left encoded.
+type testResponse struct {
+	Code    int             `json:"code"`
+	Message []string        `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// newTestStore gives the handlers an empty in-memory store.
+func newTestStore(t *testing.T) {
+	t.Helper()
+	InitStore(storage.NewMemoryStore())
+}
+
+func newTestUser(t *testing.T, username, timezone string) dto.User {
+	t.Helper()
+	user := dto.User{Username: username, Email: username + "@example.com", Timezone: timezone}
+	if err := store.Users.Create(&user, "hashed"); err != nil {
+		t.Fatalf("create user %s: %v", username, err)
+	}
+	return user
+}
+
+func newTestTarget(t *testing.T, readingTarget dto.ReadingTarget) dto.ReadingTarget {
+	t.Helper()
+	if readingTarget.Status == "" {
+		readingTarget.Status = dto.TargetStatusActive
+	}
+	if err := store.ReadingTargets.CreateWithCalendarSync(&readingTarget); err != nil {
+		t.Fatalf("create reading target: %v", err)
+	}
+	return readingTarget
+}
+
+// serve calls handler with the route variables and a JSON body, and decodes
+// the response data into data when it is not nil.
+func serve(t *testing.T, handler http.HandlerFunc, method string, vars map[string]string, body interface{}, data interface{}) testResponse {
+	t.Helper()
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			t.Fatalf("encode body: %v", err)
+		}
+	}
+	r := mux.SetURLVars(httptest.NewRequest(method, "/", &payload), vars)
+	w := httptest.NewRecorder()
+	handler(w, r)
+
+	var response testResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode response %q: %v", w.Body.String(), err)
+	}
+	if data != nil && w.Code < http.StatusBadRequest {
+		if err := json.Unmarshal(response.Data, data); err != nil {
+			t.Fatalf("decode data %s: %v", response.Data, err)
+		}
+	}
+	return response
+}
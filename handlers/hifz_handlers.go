@@ -0,0 +1,361 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/dto"
+	"github.com/daffashafwan/tadarus-yuk/internal/helpers"
+	"github.com/daffashafwan/tadarus-yuk/internal/quran"
+	"github.com/daffashafwan/tadarus-yuk/internal/storage"
+	"github.com/gorilla/mux"
+)
+
+const (
+	hifzInitialEaseFactor = 2.5
+	hifzMinEaseFactor     = 1.3
+	hifzMaxQuality        = 5
+	// hifzPassQuality is the lowest quality that keeps the repetition count
+	hifzPassQuality = 3
+)
+
+// MarkHifzPages marks pages or a verse range as memorized. New pages are
+// due for their first review the next day, pages already memorized keep
+// their schedule and only widen their verses.
+func MarkHifzPages(w http.ResponseWriter, r *http.Request) {
+	var memorizeRequest dto.HifzMemorizeRequest
+	err := json.NewDecoder(r.Body).Decode(&memorizeRequest)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	vars := mux.Vars(r)
+	user, err := getUserByUsername(vars["id"])
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Error get user", nil)
+		return
+	}
+
+	memorized, err := hifzPageRanges(memorizeRequest)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid pages or verses", nil)
+		return
+	}
+
+	location, err := userLocation(user.Timezone)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "invalid timezone", nil)
+		return
+	}
+	// The timestamps are kept in UTC
+	now := time.Now().UTC()
+	tomorrow := localDay(now, location).AddDate(0, 0, 1).Format("2006-01-02")
+
+	hifzPages := make([]dto.HifzPage, 0, len(memorized))
+	for _, hifzPage := range memorized {
+		stored, err := store.Hifz.GetByUserIDPage(user.ID, hifzPage.Page)
+		switch {
+		case err == nil:
+			if verseBefore(hifzPage.FirstVerse, stored.FirstVerse) {
+				stored.FirstVerse = hifzPage.FirstVerse
+			}
+			if verseBefore(stored.LastVerse, hifzPage.LastVerse) {
+				stored.LastVerse = hifzPage.LastVerse
+			}
+			hifzPage = stored
+		case errors.Is(err, storage.ErrNotFound):
+			hifzPage.UserID = user.ID
+			hifzPage.EaseFactor = hifzInitialEaseFactor
+			hifzPage.DueDate = tomorrow
+			hifzPage.MemorizedAt = now
+		default:
+			helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error get memorized page", nil)
+			return
+		}
+
+		if err := store.Hifz.Save(&hifzPage); err != nil {
+			helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error saving memorized page", nil)
+			return
+		}
+		hifzPages = append(hifzPages, hifzPage)
+	}
+
+	helpers.ResponseJSON(w, nil, http.StatusCreated, "SUCCESS", hifzPages)
+}
+
+func GetHifzPages(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	user, err := getUserByUsername(vars["id"])
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Error get user", nil)
+		return
+	}
+
+	hifzPages, err := store.Hifz.GetByUserID(user.ID)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching memorized pages", nil)
+		return
+	}
+
+	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", hifzPages)
+}
+
+func DeleteHifzPage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	user, err := getUserByUsername(vars["id"])
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Error get user", nil)
+		return
+	}
+
+	page, err := strconv.Atoi(vars["page"])
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Invalid page", nil)
+		return
+	}
+
+	err = store.Hifz.Delete(user.ID, page)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error deleting memorized page", nil)
+		return
+	}
+
+	helpers.ResponseJSON(w, err, http.StatusNoContent, "SUCCESS", nil)
+}
+
+// ReviewHifzPage records how well a memorized page was recited and
+// schedules its next review.
+func ReviewHifzPage(w http.ResponseWriter, r *http.Request) {
+	var reviewRequest dto.HifzReviewRequest
+	err := json.NewDecoder(r.Body).Decode(&reviewRequest)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	if reviewRequest.Quality < 0 || reviewRequest.Quality > hifzMaxQuality {
+		helpers.ResponseJSON(w, nil, http.StatusBadRequest, "quality must be between 0 and 5", nil)
+		return
+	}
+
+	vars := mux.Vars(r)
+	user, err := getUserByUsername(vars["id"])
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Error get user", nil)
+		return
+	}
+
+	hifzPage, err := store.Hifz.GetByUserIDPage(user.ID, reviewRequest.Page)
+	if errors.Is(err, storage.ErrNotFound) {
+		helpers.ResponseJSON(w, err, http.StatusNotFound, "Page "+strconv.Itoa(reviewRequest.Page)+" is not memorized", nil)
+		return
+	} else if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error get memorized page", nil)
+		return
+	}
+
+	location, err := userLocation(user.Timezone)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "invalid timezone", nil)
+		return
+	}
+	// The timestamps are kept in UTC
+	now := time.Now().UTC()
+	hifzPage = scheduleHifzReview(hifzPage, reviewRequest.Quality, localDay(now, location))
+	hifzPage.LastReviewedAt = &now
+
+	review := dto.HifzReview{
+		UserID:       user.ID,
+		Page:         hifzPage.Page,
+		Quality:      reviewRequest.Quality,
+		IntervalDays: hifzPage.IntervalDays,
+		EaseFactor:   hifzPage.EaseFactor,
+		DueDate:      hifzPage.DueDate,
+		ReviewedAt:   now,
+	}
+	err = store.Hifz.SaveReview(hifzPage, &review)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error saving review", nil)
+		return
+	}
+
+	helpers.ResponseJSON(w, err, http.StatusCreated, "SUCCESS", hifzPage)
+}
+
+func GetHifzPageReviews(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	user, err := getUserByUsername(vars["id"])
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Error get user", nil)
+		return
+	}
+
+	page, err := strconv.Atoi(vars["page"])
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Invalid page", nil)
+		return
+	}
+
+	reviews, err := store.Hifz.GetReviews(user.ID, page)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching reviews", nil)
+		return
+	}
+
+	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", reviews)
+}
+
+// GetHifzDue lists the memorized pages to revise today. The tz query
+// parameter overrides the user timezone and date replaces today.
+func GetHifzDue(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	user, err := getUserByUsername(vars["id"])
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Error get user", nil)
+		return
+	}
+
+	queryParams := r.URL.Query()
+	timezone := user.Timezone
+	if queryParams.Get("tz") != "" {
+		timezone = queryParams.Get("tz")
+	}
+	location, err := userLocation(timezone)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid timezone", nil)
+		return
+	}
+
+	today := localDay(time.Now(), location).Format("2006-01-02")
+	if date := queryParams.Get("date"); date != "" {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid date", nil)
+			return
+		}
+		today = date
+	}
+
+	hifzPages, err := store.Hifz.GetDue(user.ID, today)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching memorized pages", nil)
+		return
+	}
+
+	due := dto.HifzDue{
+		Timezone: location.String(),
+		Date:     today,
+		Pages:    hifzPages,
+	}
+	for _, hifzPage := range hifzPages {
+		if hifzPage.DueDate < today {
+			due.Overdue++
+		}
+	}
+
+	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", due)
+}
+
+// scheduleHifzReview applies SM-2: a failed recitation starts the page over
+// with a review the next day, a passed one grows the interval by the ease
+// factor, which itself follows the quality.
+func scheduleHifzReview(hifzPage dto.HifzPage, quality int, today time.Time) dto.HifzPage {
+	if quality < hifzPassQuality {
+		hifzPage.Repetitions = 0
+		hifzPage.IntervalDays = 1
+	} else {
+		switch hifzPage.Repetitions {
+		case 0:
+			hifzPage.IntervalDays = 1
+		case 1:
+			hifzPage.IntervalDays = 6
+		default:
+			hifzPage.IntervalDays = int(math.Round(float64(hifzPage.IntervalDays) * hifzPage.EaseFactor))
+		}
+		hifzPage.Repetitions++
+	}
+
+	missed := float64(hifzMaxQuality - quality)
+	hifzPage.EaseFactor += 0.1 - missed*(0.08+missed*0.02)
+	if hifzPage.EaseFactor < hifzMinEaseFactor {
+		hifzPage.EaseFactor = hifzMinEaseFactor
+	}
+	hifzPage.EaseFactor = math.Round(hifzPage.EaseFactor*100) / 100
+
+	hifzPage.DueDate = today.AddDate(0, 0, hifzPage.IntervalDays).Format("2006-01-02")
+	return hifzPage
+}
+
+// hifzPageRanges splits a memorize request into pages, each with the first
+// and last verse memorized on it.
+func hifzPageRanges(memorizeRequest dto.HifzMemorizeRequest) ([]dto.HifzPage, error) {
+	if memorizeRequest.StartVerse != "" || memorizeRequest.EndVerse != "" {
+		start, err := quran.GetVerseByKey(memorizeRequest.StartVerse)
+		if err != nil {
+			return nil, err
+		}
+		end, err := quran.GetVerseByKey(memorizeRequest.EndVerse)
+		if err != nil {
+			return nil, err
+		}
+		if verseBefore(end.Key, start.Key) {
+			return nil, errors.New("endVerse is before startVerse")
+		}
+
+		var hifzPages []dto.HifzPage
+		for page := start.Page; page <= end.Page; page++ {
+			verses, err := quran.GetPageVerses(page)
+			if err != nil {
+				return nil, err
+			}
+			hifzPage := dto.HifzPage{Page: page, FirstVerse: verses[0].Key, LastVerse: verses[len(verses)-1].Key}
+			if page == start.Page {
+				hifzPage.FirstVerse = start.Key
+			}
+			if page == end.Page {
+				hifzPage.LastVerse = end.Key
+			}
+			hifzPages = append(hifzPages, hifzPage)
+		}
+		return hifzPages, nil
+	}
+
+	pages := memorizeRequest.Pages
+	if len(pages) == 0 {
+		if memorizeRequest.StartPage == 0 && memorizeRequest.EndPage == 0 {
+			return nil, errors.New("pages, a valid page range or a verse range is required")
+		}
+		if memorizeRequest.StartPage < 1 || memorizeRequest.EndPage > quran.TotalPages || memorizeRequest.EndPage < memorizeRequest.StartPage {
+			return nil, fmt.Errorf("page range must be within 1 and %d", quran.TotalPages)
+		}
+		for page := memorizeRequest.StartPage; page <= memorizeRequest.EndPage; page++ {
+			pages = append(pages, page)
+		}
+	}
+
+	var hifzPages []dto.HifzPage
+	seen := make(map[int]bool)
+	for _, page := range pages {
+		verses, err := quran.GetPageVerses(page)
+		if err != nil {
+			return nil, err
+		}
+		if seen[page] {
+			continue
+		}
+		seen[page] = true
+		hifzPages = append(hifzPages, dto.HifzPage{Page: page, FirstVerse: verses[0].Key, LastVerse: verses[len(verses)-1].Key})
+	}
+	return hifzPages, nil
+}
+
+// verseBefore reports whether verse key a comes before b in the mushaf.
+func verseBefore(a, b string) bool {
+	surahA, verseA, _ := quran.ParseVerseKey(a)
+	surahB, verseB, _ := quran.ParseVerseKey(b)
+	return surahA < surahB || (surahA == surahB && verseA < verseB)
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/dto"
+	"github.com/daffashafwan/tadarus-yuk/internal/helpers"
+	"github.com/daffashafwan/tadarus-yuk/internal/hijri"
+	"github.com/gorilla/mux"
+)
+
+// GetHijriMonth returns the Gregorian range of a Hijri month, for planning a
+// target over Ramadan or Dhul Hijjah.
+func GetHijriMonth(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	year, err := strconv.Atoi(vars["year"])
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid year", nil)
+		return
+	}
+	month, err := strconv.Atoi(vars["month"])
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid month", nil)
+		return
+	}
+
+	startDate, endDate, err := hijri.MonthRange(year, month)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid hijri month", nil)
+		return
+	}
+
+	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", dto.HijriMonth{
+		Year:      year,
+		Month:     month,
+		Name:      hijri.MonthName(month),
+		Days:      hijri.MonthLength(year, month),
+		StartDate: startDate.Format("2006-01-02"),
+		EndDate:   endDate.Format("2006-01-02"),
+	})
+}
+
+// resolveHijriDates fills StartDate and EndDate from the Hijri dates when
+// only those are given.
+func resolveHijriDates(readingTarget *dto.ReadingTarget) error {
+	if readingTarget.StartDate == "" && readingTarget.HijriStartDate != "" {
+		startDate, err := gregorianDate(readingTarget.HijriStartDate)
+		if err != nil {
+			return err
+		}
+		readingTarget.StartDate = startDate
+	}
+	if readingTarget.EndDate == "" && readingTarget.HijriEndDate != "" {
+		endDate, err := gregorianDate(readingTarget.HijriEndDate)
+		if err != nil {
+			return err
+		}
+		readingTarget.EndDate = endDate
+	}
+	return nil
+}
+
+// gregorianDate converts a YYYY-MM-DD Hijri date to a YYYY-MM-DD Gregorian one.
+func gregorianDate(hijriDate string) (string, error) {
+	date, err := hijri.Parse(hijriDate)
+	if err != nil {
+		return "", err
+	}
+	return hijri.ToGregorian(date).Format("2006-01-02"), nil
+}
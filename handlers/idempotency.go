@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/dto"
+	"github.com/daffashafwan/tadarus-yuk/internal/helpers"
+)
+
+const (
+	idempotencyKeyHeader    = "Idempotency-Key"
+	idempotencyKeyMaxLength = 255
+	idempotencyKeyTTL       = 24 * time.Hour
+)
+
+// Idempotent replays the stored response when a request is retried with the
+// same Idempotency-Key header, so the wrapped handler runs at most once per
+// key. Requests without the header are passed through.
+func Idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		if key == "" {
+			next(w, r)
+			return
+		}
+		if len(key) > idempotencyKeyMaxLength {
+			helpers.ResponseJSON(w, nil, http.StatusBadRequest, "Idempotency-Key is too long", nil)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			helpers.ResponseJSON(w, err, http.StatusBadRequest, "Invalid request body", nil)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		hash := sha256.Sum256(body)
+
+		// Keys are scoped to the endpoint, the path carries the user and target
+		record := dto.IdempotencyRecord{
+			Key:         r.Method + " " + r.URL.Path + " " + key,
+			RequestHash: hex.EncodeToString(hash[:]),
+		}
+		requestHash := record.RequestHash
+		reserved, err := store.Idempotency.Reserve(&record, time.Now().Add(-idempotencyKeyTTL))
+		if err != nil {
+			helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error checking Idempotency-Key", nil)
+			return
+		}
+
+		if !reserved {
+			switch {
+			case record.RequestHash != requestHash:
+				helpers.ResponseJSON(w, nil, http.StatusUnprocessableEntity, "Idempotency-Key was used with a different request body", nil)
+			case record.StatusCode == 0:
+				helpers.ResponseJSON(w, nil, http.StatusConflict, "request with this Idempotency-Key is still in progress", nil)
+			default:
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(record.StatusCode)
+				w.Write(record.Response)
+			}
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		next(recorder, r)
+
+		// Server errors are not remembered so the client can retry them
+		if recorder.statusCode >= http.StatusInternalServerError {
+			if err := store.Idempotency.Release(record.Key); err != nil {
+				log.Printf("Error : %v", err.Error())
+			}
+			return
+		}
+
+		record.StatusCode = recorder.statusCode
+		record.Response = recorder.body.Bytes()
+		if err := store.Idempotency.Complete(record); err != nil {
+			log.Printf("Error : %v", err.Error())
+		}
+	}
+}
+
+// responseRecorder passes the response through while keeping a copy of it.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(statusCode int) {
+	rec.statusCode = statusCode
+	rec.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
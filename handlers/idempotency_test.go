@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/helpers"
+)
+
+func TestIdempotent(t *testing.T) {
+	newTestStore(t)
+	calls := 0
+	handler := Idempotent(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		helpers.ResponseJSON(w, nil, http.StatusCreated, "SUCCESS", calls)
+	})
+	request := func(body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/api/users/fulan/reading-targets/1/reading-progress", strings.NewReader(body))
+		r.Header.Set(idempotencyKeyHeader, "key-1")
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w
+	}
+
+	first := request(`{"currentPage": 3}`)
+	retried := request(`{"currentPage": 3}`)
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+	if retried.Code != first.Code || retried.Body.String() != first.Body.String() || retried.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("retry = %d %s, want the replayed %d %s", retried.Code, retried.Body, first.Code, first.Body)
+	}
+
+	if other := request(`{"currentPage": 4}`); other.Code != http.StatusUnprocessableEntity {
+		t.Errorf("other body: status = %d, want %d", other.Code, http.StatusUnprocessableEntity)
+	}
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/dto"
+	"github.com/daffashafwan/tadarus-yuk/internal/helpers"
+	"github.com/daffashafwan/tadarus-yuk/internal/quran"
+	"github.com/daffashafwan/tadarus-yuk/internal/storage"
+	"github.com/gorilla/mux"
+)
+
+// GetKhatamHistory returns every target the user has completed and how many
+// there are.
+func GetKhatamHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	user, err := getUserByUsername(vars["id"])
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Error get user", nil)
+		return
+	}
+
+	khatams, err := store.Khatams.GetByUserID(user.ID)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching khatam history", nil)
+		return
+	}
+
+	history := dto.KhatamHistory{Total: len(khatams), Khatams: khatams}
+	for i := range history.Khatams {
+		history.Khatams[i].FullQuran = isFullQuran(history.Khatams[i].StartPage, history.Khatams[i].EndPage)
+		if history.Khatams[i].FullQuran {
+			history.FullQuran++
+		}
+	}
+
+	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", history)
+}
+
+// recordKhatam stores a completion and marks the target completed once every
+// page of it is read. It is called after progress is written, so a failure
+// is only logged and the next entry on the target tries again.
+func recordKhatam(readingTarget dto.ReadingTarget) {
+	if readingTarget.Status == dto.TargetStatusReassigned {
+		return
+	}
+	khatam, completed, err := detectKhatam(readingTarget)
+	if err != nil {
+		log.Printf("Error : %v", err.Error())
+		return
+	}
+	if !completed {
+		return
+	}
+
+	err = store.Khatams.Create(&khatam)
+	if err != nil && err != storage.ErrConflict {
+		log.Printf("Error : %v", err.Error())
+	}
+
+	if readingTarget.Status != dto.TargetStatusCompleted {
+		readingTarget.Status = dto.TargetStatusCompleted
+		readingTarget.PausedAt = nil
+		if err := updateReadingTarget(readingTarget); err != nil {
+			log.Printf("Error : %v", err.Error())
+		}
+	}
+}
+
+// detectKhatam builds the completion of a target, completed is false while
+// a page of it is not read.
+func detectKhatam(readingTarget dto.ReadingTarget) (dto.Khatam, bool, error) {
+	progresses, err := getReadingProgressByUserIDTargetID(readingTarget.UserID, readingTarget.ID)
+	if err != nil {
+		return dto.Khatam{}, false, err
+	}
+	progresses = firstReadings(progresses)
+	completedAt, completed := newPageCoverage(progresses).CompletedAt(readingTarget.StartPage, readingTarget.EndPage)
+	if !completed {
+		return dto.Khatam{}, false, nil
+	}
+
+	startedAt := completedAt
+	for _, readingProgress := range progresses {
+		if readingProgress.ReadAt.Before(startedAt) {
+			startedAt = readingProgress.ReadAt
+		}
+	}
+
+	user, err := getUserByIDWithoutEncrypt(readingTarget.UserID)
+	if err != nil {
+		return dto.Khatam{}, false, err
+	}
+	location, err := userLocation(user.Timezone)
+	if err != nil {
+		return dto.Khatam{}, false, err
+	}
+
+	return dto.Khatam{
+		UserID:       readingTarget.UserID,
+		TargetID:     readingTarget.ID,
+		TargetName:   readingTarget.Name,
+		StartPage:    readingTarget.StartPage,
+		EndPage:      readingTarget.EndPage,
+		StartedAt:    startedAt,
+		CompletedAt:  completedAt,
+		DurationDays: daysBetween(localDay(startedAt, location), localDay(completedAt, location)) + 1,
+	}, true, nil
+}
+
+func isFullQuran(startPage, endPage int) bool {
+	return startPage == 1 && endPage == quran.TotalPages
+}
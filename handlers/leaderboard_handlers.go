@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"net/http"
 	"sort"
-	"strconv"
 	"strings"
 	"time"
 
@@ -17,12 +16,23 @@ var (
 	leaderboardCache = make(map[string]dto.Leaderboard)
 )
 
+// GetLeaderboard ranks the public targets by pages per day. The kind query
+// parameter picks first readings (default) or reviews.
 func GetLeaderboard(w http.ResponseWriter, r *http.Request) {
 	queryParams := r.URL.Query()
 	leaderboardType := queryParams.Get("type")
 	userID := queryParams.Get("userID")
+	kind := queryParams.Get("kind")
+	if kind == "" {
+		kind = dto.ProgressKindRead
+	}
+	if err := validateProgressKind(kind); err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Error get leaderboard", nil)
+		return
+	}
+	cacheKey := leaderboardType + ":" + kind
 
-	progress := make(map[int]int)
+	progress := make(map[int]float64)
 	now := time.Now()
 
 	var startTime, endTime time.Time
@@ -63,18 +73,20 @@ func GetLeaderboard(w http.ResponseWriter, r *http.Request) {
 	}
 
 	for _, rp := range readingProgress {
-		progress[rp.UserID]++
+		if rp.Kind == kind {
+			progress[rp.UserID] += rp.PageShare
+		}
 	}
 
 	var progressSlice []struct {
 		Key   int
-		Value int
+		Value float64
 	}
 
 	for k, v := range progress {
 		progressSlice = append(progressSlice, struct {
 			Key   int
-			Value int
+			Value float64
 		}{k, v})
 	}
 
@@ -86,7 +98,7 @@ func GetLeaderboard(w http.ResponseWriter, r *http.Request) {
 	for _, val := range progressSlice {
 		details := getReadingTargetByUserIDForLeaderboard(val.Key, readingTargets)
 		user, _ := getUserByIDWithoutEncrypt(val.Key)
-		pace := float64(val.Value) / divider
+		pace := val.Value / divider
 		paceFormatted := fmt.Sprintf("%.3f", pace)
 		ranks = append(ranks, dto.Rank{
 			Username: user.DisplayName,
@@ -95,17 +107,18 @@ func GetLeaderboard(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
-	if _, ok := leaderboardCache[leaderboardType]; !ok {
-		leaderboardCache[leaderboardType] = dto.Leaderboard{}
+	if _, ok := leaderboardCache[cacheKey]; !ok {
+		leaderboardCache[cacheKey] = dto.Leaderboard{}
 	}
 
-	leaderboardCache[leaderboardType] = dto.Leaderboard{
+	leaderboardCache[cacheKey] = dto.Leaderboard{
 		Type:        leaderboardType,
+		Kind:        kind,
 		Ranks:       ranks,
 		LastUpdated: now,
 	}
 
-	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", leaderboardCache[leaderboardType])
+	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", leaderboardCache[cacheKey])
 }
 
 func getReadingTargetByUserIDForLeaderboard(userID int, readingTarget []dto.ReadingTarget) []dto.Detail {
@@ -121,7 +134,7 @@ func getReadingTargetByUserIDForLeaderboard(userID int, readingTarget []dto.Read
 			endDate := strings.Split(rt.EndDate, "T")
 			result = append(result, dto.Detail{
 				ReadingTargetName:        rt.Name,
-				ReadingTargetDescription: "Halaman " + strconv.Itoa(rt.StartPage) + " - " + strconv.Itoa(rt.EndPage),
+				ReadingTargetDescription: dto.TargetLabel(rt.Scope, rt.StartPage, rt.EndPage),
 				ReadingTargetDate:        "Mulai : " + startDate[0] + ", Selesai : " + endDate[0],
 				ReadingTargetProgress:    rt.Progress,
 			})
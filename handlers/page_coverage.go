@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/dto"
+	"github.com/daffashafwan/tadarus-yuk/internal/quran"
+)
+
+// pageCoverage tracks which verses of each page the first readings of a
+// target cover. A page is complete once every verse on it is covered,
+// whether by a whole page entry or by verse ranges.
+type pageCoverage struct {
+	verses    map[int]map[string]bool
+	completed map[int]time.Time
+}
+
+// newPageCoverage replays the first readings from oldest to newest, so
+// completed holds the read time of the entry that finished each page.
+func newPageCoverage(readingProgresses []dto.ReadingProgress) *pageCoverage {
+	coverage := &pageCoverage{
+		verses:    make(map[int]map[string]bool),
+		completed: make(map[int]time.Time),
+	}
+
+	readings := firstReadings(readingProgresses)
+	sort.SliceStable(readings, func(i, j int) bool { return readings[i].ReadAt.Before(readings[j].ReadAt) })
+	for _, readingProgress := range readings {
+		verses, err := progressVerses(readingProgress)
+		if err != nil {
+			continue
+		}
+		for _, verse := range verses {
+			if coverage.verses[verse.Page] == nil {
+				coverage.verses[verse.Page] = make(map[string]bool)
+			}
+			coverage.verses[verse.Page][verse.Key] = true
+		}
+		for _, verse := range verses {
+			if _, ok := coverage.completed[verse.Page]; !ok && coverage.Share(verse.Page) == 1 {
+				coverage.completed[verse.Page] = readingProgress.ReadAt
+			}
+		}
+	}
+	return coverage
+}
+
+// Complete reports whether every verse of page is covered.
+func (c *pageCoverage) Complete(page int) bool {
+	_, ok := c.completed[page]
+	return ok
+}
+
+// Share returns the covered part of page, from 0 to 1.
+func (c *pageCoverage) Share(page int) float64 {
+	if len(c.verses[page]) == 0 {
+		return 0
+	}
+	verses, err := quran.GetPageVerses(page)
+	if err != nil {
+		return 0
+	}
+	return float64(len(c.verses[page])) / float64(len(verses))
+}
+
+// CompletedAt returns when the last page from startPage to endPage was
+// completed, ok is false while any of them is not.
+func (c *pageCoverage) CompletedAt(startPage, endPage int) (completedAt time.Time, ok bool) {
+	for page := startPage; page <= endPage; page++ {
+		pageCompletedAt, done := c.completed[page]
+		if !done {
+			return time.Time{}, false
+		}
+		if pageCompletedAt.After(completedAt) {
+			completedAt = pageCompletedAt
+		}
+	}
+	return completedAt, endPage >= startPage
+}
+
+// PagesRead sums the covered share of the pages from startPage to endPage.
+func (c *pageCoverage) PagesRead(startPage, endPage int) float64 {
+	var pages float64
+	for page := range c.verses {
+		if page >= startPage && page <= endPage {
+			pages += c.Share(page)
+		}
+	}
+	return pages
+}
+
+// UncoveredShare returns how many pages the uncovered verses among verses
+// add up to.
+func (c *pageCoverage) UncoveredShare(verses []quran.Verse) float64 {
+	var share float64
+	for _, verse := range verses {
+		if c.verses[verse.Page][verse.Key] {
+			continue
+		}
+		pageVerses, err := quran.GetPageVerses(verse.Page)
+		if err != nil {
+			continue
+		}
+		share += 1 / float64(len(pageVerses))
+	}
+	return roundPageShare(share)
+}
+
+// progressVerses returns the verses an entry covers, every verse of its page
+// when it has no verse range.
+func progressVerses(readingProgress dto.ReadingProgress) ([]quran.Verse, error) {
+	if readingProgress.StartVerse == "" {
+		return quran.GetPageVerses(readingProgress.CurrentPage)
+	}
+	return quran.GetVerseRange(readingProgress.StartVerse, readingProgress.EndVerse)
+}
+
+// progressLastPage returns the page an entry stops on.
+func progressLastPage(readingProgress dto.ReadingProgress) int {
+	if readingProgress.EndVerse == "" {
+		return readingProgress.CurrentPage
+	}
+	verse, err := quran.GetVerseByKey(readingProgress.EndVerse)
+	if err != nil {
+		return readingProgress.CurrentPage
+	}
+	return verse.Page
+}
+
+// roundPageShare keeps the four decimals stored for a page share.
+func roundPageShare(share float64) float64 {
+	return math.Round(share*10000) / 10000
+}
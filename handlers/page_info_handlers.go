@@ -1,14 +1,16 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"github.com/daffashafwan/tadarus-yuk/external"
 	"github.com/daffashafwan/tadarus-yuk/internal/dto"
 	"github.com/daffashafwan/tadarus-yuk/internal/helpers"
+	"github.com/daffashafwan/tadarus-yuk/internal/quran"
 	"github.com/gorilla/mux"
 )
 
@@ -17,68 +19,104 @@ func GetPageInfoByPageNumber(w http.ResponseWriter, r *http.Request) {
 	pageNum := vars["pageNum"]
 
 	pageNumConv, err := strconv.Atoi(pageNum)
-	if err != nil || (pageNumConv < 0 || pageNumConv > 604) {
+	if err != nil || (pageNumConv < 1 || pageNumConv > quran.TotalPages) {
 		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Wrong Page Number", nil)
 		return
 	}
 
-	pageInfo, err := getPageInfo(pageNum)
+	pageInfo, err := getPageInfo(r.Context(), pageNumConv, r.URL.Query().Get("withText") == "true")
 	if err != nil {
-		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error get page info  ID", nil)
+		helpers.ResponseJSON(w, err, quranAPIErrorStatus(err), "Error get page info  ID", nil)
 		return
 	}
 
 	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", pageInfo)
 }
 
-func getPageInfo(page string) (dto.PageInfo, error) {
-	var pageInfo dto.PageInfo
-	chapterInfos := make([]dto.ChapterInfo, 0)
-	pageRes, err := external.GetQuranAPIPages(page)
+// getPageInfo builds the page info from the embedded dataset, the surah
+// descriptions are only fetched from the Quran API when withText is set.
+func getPageInfo(ctx context.Context, page int, withText bool) (dto.PageInfo, error) {
+	verses, err := quran.GetPageVerses(page)
 	if err != nil {
-		log.Printf("[GetQuranCloudPages] error get page %s, with error : %s", page, err.Error())
 		return dto.PageInfo{}, err
 	}
-	pageInfo.NumberOfVerses = len(pageRes.Verses)
-	currentChapter := "0"
-	for _, verse := range pageRes.Verses {
-		result := strings.Split(verse.VerseKey, ":")
-		currentChapter = result[0]
-		surah, err := external.GetQuranAPIChapter(currentChapter)
-		if err != nil {
-			log.Printf("[GetQuranAPIChapter] error get chapter %s from page %s, with error : %s", currentChapter, page, err.Error())
-			continue
+
+	pageInfo := dto.PageInfo{
+		PageNumber:     page,
+		FirstVerse:     verses[0].Key,
+		LastVerse:      verses[len(verses)-1].Key,
+		Juz:            verses[0].Juz,
+		Hizb:           verses[0].Hizb,
+		Manzil:         verses[0].Manzil,
+		NumberOfVerses: len(verses),
+	}
+
+	chapterInfos := make([]dto.ChapterInfo, 0)
+	for _, verse := range verses {
+		if verse.Sajdah != "" {
+			pageInfo.AyatSajdah++
 		}
-		chapterInfo := dto.ChapterInfo{
-			Name:            surah.Chapter.NameSimple,
-			VersesCount:     surah.Chapter.VersesCount,
-			RevelationPlace: surah.Chapter.RevelationPlace,
+		if len(chapterInfos) > 0 && chapterInfos[len(chapterInfos)-1].ID == verse.Surah {
+			continue
 		}
 
-		surahInfo, err := external.GetQuranAPIChapterInfo(strconv.Itoa(surah.Chapter.ID))
+		surah, err := quran.GetSurah(verse.Surah)
 		if err != nil {
-			log.Printf("[GetQuranAPIChapter] error get info from chapter %s from page %s, with error : %s", currentChapter, page, err.Error())
-			continue
+			return dto.PageInfo{}, err
 		}
-		chapterInfo.SourceText = surahInfo.ChapterInfo.Source
-		chapterInfo.Text = surahInfo.ChapterInfo.Text
-		if surahInfo.ChapterInfo.ShortText != "" {
-			chapterInfo.Text = surahInfo.ChapterInfo.ShortText
+		chapterInfo := dto.ChapterInfo{
+			ID:              surah.Number,
+			Name:            surah.Name,
+			NameArabic:      surah.NameArabic,
+			VersesCount:     surah.VersesCount,
+			RevelationPlace: surah.RevelationPlace,
 		}
-		if !isSurahAlreadyExist(chapterInfos, chapterInfo.Name) {
-			chapterInfos = append(chapterInfos, chapterInfo)
+		if withText {
+			if err := setChapterText(ctx, &chapterInfo, page); err != nil {
+				return dto.PageInfo{}, err
+			}
 		}
+		chapterInfos = append(chapterInfos, chapterInfo)
 	}
 	pageInfo.Surahs = chapterInfos
 
 	return pageInfo, nil
 }
 
-func isSurahAlreadyExist(chapterInfos []dto.ChapterInfo, surahName string) bool {
-	for _, obj := range chapterInfos {
-		if obj.Name == surahName {
-			return true
-		}
+// setChapterText fills the surah description, it is left empty when no
+// provider has one but upstream failures are returned.
+func setChapterText(ctx context.Context, chapterInfo *dto.ChapterInfo, page int) error {
+	surahInfo, err := external.GetQuranAPIChapterInfo(ctx, strconv.Itoa(chapterInfo.ID))
+	var apiErr *external.QuranAPIError
+	if errors.As(err, &apiErr) {
+		return err
+	} else if err != nil {
+		log.Printf("[GetQuranAPIChapterInfo] error get info from chapter %d from page %d, with error : %s", chapterInfo.ID, page, err.Error())
+		return nil
+	}
+	chapterInfo.SourceText = surahInfo.ChapterInfo.Source
+	chapterInfo.Text = surahInfo.ChapterInfo.Text
+	if surahInfo.ChapterInfo.ShortText != "" {
+		chapterInfo.Text = surahInfo.ChapterInfo.ShortText
+	}
+	return nil
+}
+
+// quranAPIErrorStatus maps upstream Quran API failures to 502 or 504.
+func quranAPIErrorStatus(err error) int {
+	var apiErr *external.QuranAPIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatus()
 	}
-	return false
+	return http.StatusInternalServerError
+}
+
+func GetQuranCacheStats(w http.ResponseWriter, r *http.Request) {
+	stats, ok := external.GetQuranCacheStats()
+	if !ok {
+		helpers.ResponseJSON(w, nil, http.StatusNotFound, "Quran cache is disabled", nil)
+		return
+	}
+
+	helpers.ResponseJSON(w, nil, http.StatusOK, "SUCCESS", stats)
 }
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/dto"
+	"github.com/daffashafwan/tadarus-yuk/internal/helpers"
+	"github.com/gorilla/mux"
+)
+
+// GetReadingPlan returns the day by day schedule of a reading target. Today
+// is taken in the owner's timezone, the optional date query parameter
+// replaces it, e.g. ?date=2024-03-01.
+func GetReadingPlan(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	readingTargetID := vars["id"]
+
+	readingTarget, err := getReadingTargetByID(readingTargetID)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching get reading target by ID", nil)
+		return
+	}
+
+	location := targetLocation(readingTarget.UserID)
+	today := localDay(time.Now(), location)
+	if date := r.URL.Query().Get("date"); date != "" {
+		parsed, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid date", nil)
+			return
+		}
+		today = parsed
+	}
+
+	progresses, err := getReadingProgressByUserIDTargetID(readingTarget.UserID, readingTarget.ID)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error getting reading progress in target", nil)
+		return
+	}
+
+	plan, err := buildReadingPlan(readingTarget, progresses, today, location)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid date or page range in reading target", nil)
+		return
+	}
+
+	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", plan)
+}
+
+// buildReadingPlan keeps the original even split for the days before today
+// and spreads the unread pages over today and the days left. A page read
+// verse by verse counts once all its verses are read, on its day in
+// location.
+func buildReadingPlan(readingTarget dto.ReadingTarget, progresses []dto.ReadingProgress, today time.Time, location *time.Location) (dto.ReadingPlan, error) {
+	startDate, err := time.Parse("2006-01-02", dateOnly(readingTarget.StartDate))
+	if err != nil {
+		return dto.ReadingPlan{}, err
+	}
+	endDate, err := time.Parse("2006-01-02", dateOnly(readingTarget.EndDate))
+	if err != nil {
+		return dto.ReadingPlan{}, err
+	}
+	if endDate.Before(startDate) || readingTarget.EndPage < readingTarget.StartPage {
+		return dto.ReadingPlan{}, errors.New("reading target ends before it starts")
+	}
+
+	todayStr := today.Format("2006-01-02")
+	todayDate, _ := time.Parse("2006-01-02", todayStr)
+	totalPages := readingTarget.EndPage - readingTarget.StartPage + 1
+	totalDays := daysBetween(startDate, endDate) + 1
+	todayIndex := daysBetween(startDate, todayDate)
+
+	// Count every page once, on the day it was completed
+	read := make(map[int]bool)
+	readOn := make(map[string]int)
+	for page, readAt := range newPageCoverage(progresses).completed {
+		if page < readingTarget.StartPage || page > readingTarget.EndPage {
+			continue
+		}
+		read[page] = true
+		readOn[localDay(readAt, location).Format("2006-01-02")]++
+	}
+
+	unread := make([]int, 0, totalPages-len(read))
+	for page := readingTarget.StartPage; page <= readingTarget.EndPage; page++ {
+		if !read[page] {
+			unread = append(unread, page)
+		}
+	}
+
+	plannedUntil := func(day int) int {
+		if day < 0 {
+			return 0
+		}
+		if day >= totalDays {
+			return totalPages
+		}
+		return splitPages(totalPages, totalDays, day)
+	}
+
+	plan := dto.ReadingPlan{
+		TargetID:       readingTarget.ID,
+		Date:           todayStr,
+		StartDate:      startDate.Format("2006-01-02"),
+		EndDate:        endDate.Format("2006-01-02"),
+		TotalPages:     totalPages,
+		PagesRead:      len(read),
+		PagesRemaining: len(unread),
+		ExpectedRead:   plannedUntil(todayIndex),
+		DailyPages:     math.Round(float64(totalPages)/float64(totalDays)*10) / 10,
+		Days:           make([]dto.ReadingPlanDay, 0, totalDays),
+	}
+
+	switch {
+	case len(unread) == 0:
+		plan.Status = dto.ReadingPlanCompleted
+	case plan.PagesRead < plannedUntil(todayIndex-1):
+		plan.Status = dto.ReadingPlanBehind
+	case plan.PagesRead > plan.ExpectedRead:
+		plan.Status = dto.ReadingPlanAhead
+	default:
+		plan.Status = dto.ReadingPlanOnTrack
+	}
+	if plan.ExpectedRead > plan.PagesRead {
+		plan.CatchUpPages = plan.ExpectedRead - plan.PagesRead
+	}
+
+	// Pages read before the target started count towards the first day
+	readSoFar := 0
+	for date, count := range readOn {
+		if date < plan.StartDate {
+			readSoFar += count
+		}
+	}
+
+	firstOpenDay := todayIndex
+	if firstOpenDay < 0 {
+		firstOpenDay = 0
+	}
+	if firstOpenDay < totalDays {
+		plan.DaysRemaining = totalDays - firstOpenDay
+	}
+
+	// Today's share includes the pages already read today, so reading does
+	// not push more of the remaining pages into today.
+	readToday := 0
+	if todayIndex >= 0 && todayIndex < totalDays {
+		readToday = readOn[todayStr]
+	}
+	if plan.DaysRemaining > 0 {
+		plan.CatchUpDailyPages = splitPages(len(unread)+readToday, plan.DaysRemaining, 0)
+	} else {
+		plan.CatchUpDailyPages = len(unread)
+	}
+
+	remaining := unread
+	for day := 0; day < totalDays; day++ {
+		date := startDate.AddDate(0, 0, day).Format("2006-01-02")
+		planDay := dto.ReadingPlanDay{Date: date, PagesRead: readOn[date]}
+
+		if day < firstOpenDay {
+			from := plannedUntil(day - 1)
+			planDay.StartPage = readingTarget.StartPage + from
+			planDay.EndPage = readingTarget.StartPage + plannedUntil(day) - 1
+			planDay.Pages = plannedUntil(day) - from
+
+			readSoFar += planDay.PagesRead
+			switch {
+			case readSoFar < plannedUntil(day):
+				planDay.Status = dto.ReadingPlanBehind
+			case readSoFar > plannedUntil(day):
+				planDay.Status = dto.ReadingPlanAhead
+			default:
+				planDay.Status = dto.ReadingPlanOnTrack
+			}
+			plan.Days = append(plan.Days, planDay)
+			continue
+		}
+
+		var chunk []int
+		if day == todayIndex {
+			planDay.Pages = plan.CatchUpDailyPages
+			left := planDay.Pages - readToday
+			if left < 0 {
+				left = 0
+			}
+			if left > len(remaining) {
+				left = len(remaining)
+			}
+			chunk, remaining = remaining[:left], remaining[left:]
+		} else {
+			openDays := totalDays - day
+			size := splitPages(len(remaining), openDays, 0)
+			chunk, remaining = remaining[:size], remaining[size:]
+			planDay.Pages = len(chunk)
+		}
+
+		planDay.Status = dto.ReadingPlanPlanned
+		if len(chunk) > 0 {
+			planDay.StartPage = chunk[0]
+			planDay.EndPage = chunk[len(chunk)-1]
+		} else if planDay.Pages > 0 || len(unread) == 0 {
+			planDay.Status = dto.ReadingPlanCompleted
+		}
+		plan.Days = append(plan.Days, planDay)
+	}
+
+	return plan, nil
+}
+
+// splitPages returns how many of pages fall on the days up to and including
+// day when they are spread evenly over days, earlier days taking the extra.
+func splitPages(pages, days, day int) int {
+	extra := pages % days
+	if extra > day+1 {
+		extra = day + 1
+	}
+	return (day+1)*(pages/days) + extra
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(math.Round(to.Sub(from).Hours() / 24))
+}
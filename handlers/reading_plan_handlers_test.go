@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/dto"
+)
+
+func TestBuildReadingPlanCountsReadsOnTheLocalDay(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Skipf("no tz data: %v", err)
+	}
+	readingTarget := dto.ReadingTarget{ID: 1, StartDate: "2026-10-01", EndDate: "2026-10-10", StartPage: 1, EndPage: 20, Pages: 20}
+	// 06:00 in Jakarta on the 6th is still the 5th in UTC
+	readAt := time.Date(2026, 10, 5, 23, 0, 0, 0, time.UTC)
+	progresses := []dto.ReadingProgress{{ID: 1, TargetID: 1, CurrentPage: 1, ReadAt: readAt, Kind: dto.ProgressKindRead}}
+	today := time.Date(2026, 10, 6, 0, 0, 0, 0, time.UTC)
+
+	plan, err := buildReadingPlan(readingTarget, progresses, today, jakarta)
+	if err != nil {
+		t.Fatalf("build plan: %v", err)
+	}
+	for _, day := range plan.Days {
+		want := 0
+		if day.Date == "2026-10-06" {
+			want = 1
+		}
+		if day.PagesRead != want {
+			t.Errorf("%s pages read = %d, want %d", day.Date, day.PagesRead, want)
+		}
+	}
+}
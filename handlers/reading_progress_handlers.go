@@ -1,41 +1,79 @@
 package handlers
 
 import (
-	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
 	"strconv"
 	"time"
 
-	"github.com/daffashafwan/tadarus-yuk/db"
 	"github.com/daffashafwan/tadarus-yuk/internal/dto"
 	"github.com/daffashafwan/tadarus-yuk/internal/helpers"
+	"github.com/daffashafwan/tadarus-yuk/internal/quran"
+	"github.com/daffashafwan/tadarus-yuk/internal/storage"
 	"github.com/gorilla/mux"
 )
 
+const (
+	defaultProgressBackdateLimit = 72 * time.Hour
+	// progressClockSkew tolerates client clocks running a little ahead
+	progressClockSkew = 5 * time.Minute
+)
+
+var progressBackdateLimit = defaultProgressBackdateLimit
+
+// InitReadingProgress reads how far back a progress entry may be dated.
+func InitReadingProgress() {
+	if val, err := strconv.Atoi(os.Getenv("PROGRESS_BACKDATE_LIMIT_HOURS")); err == nil && val >= 0 {
+		progressBackdateLimit = time.Duration(val) * time.Hour
+	}
+}
+
+// validateReadAt checks a client read time, the zero time means now.
+func validateReadAt(readAt, now time.Time) error {
+	if readAt.IsZero() {
+		return nil
+	}
+	if readAt.After(now.Add(progressClockSkew)) {
+		return errors.New("readAt is in the future")
+	}
+	if readAt.Before(now.Add(-progressBackdateLimit)) {
+		return fmt.Errorf("readAt can not be more than %d hours ago", int(progressBackdateLimit.Hours()))
+	}
+	return nil
+}
+
+// validateProgressKind checks the kind of a new entry, empty means read.
+func validateProgressKind(kind string) error {
+	switch kind {
+	case "", dto.ProgressKindRead, dto.ProgressKindReview:
+		return nil
+	}
+	return fmt.Errorf("kind must be %s or %s", dto.ProgressKindRead, dto.ProgressKindReview)
+}
+
+// firstReadings leaves out the review entries, only first readings count
+// towards the completion of a target.
+func firstReadings(readingProgresses []dto.ReadingProgress) []dto.ReadingProgress {
+	result := make([]dto.ReadingProgress, 0, len(readingProgresses))
+	for _, readingProgress := range readingProgresses {
+		if readingProgress.Kind != dto.ProgressKindReview {
+			result = append(result, readingProgress)
+		}
+	}
+	return result
+}
+
 func GetAllReadingProgress(w http.ResponseWriter, r *http.Request) {
 	// Query all reading_progress from the database
-	query := "SELECT * FROM reading_progress"
-	rows, err := db.GetDB().Query(query)
+	readingProgresss, err := store.ReadingProgress.GetAll()
 	if err != nil {
 		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching reading progress", nil)
 		return
 	}
-	defer rows.Close()
-
-	var readingProgresss []dto.ReadingProgress
-	for rows.Next() {
-		var readingProgress dto.ReadingProgress
-		err := rows.Scan(&readingProgress.ID, &readingProgress.UserID, &readingProgress.TargetID, &readingProgress.CurrentPage, &readingProgress.TimeStamp)
-		if err != nil {
-			helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching get all reading progress", nil)
-			return
-		}
-		readingProgresss = append(readingProgresss, readingProgress)
-	}
 
 	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", readingProgresss)
 	
@@ -55,6 +93,8 @@ func GetReadingProgressByID(w http.ResponseWriter, r *http.Request) {
 	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", readingProgressRes)
 }
 
+// UpdateReadingProgressByID moves an entry to another page or verse range of
+// its target, checked and shared out like a new entry.
 func UpdateReadingProgressByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	readingProgressID := vars["id"]
@@ -72,14 +112,47 @@ func UpdateReadingProgressByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	readingProgress.CurrentPage = readingProgressUpdate.CurrentPage
+	readingTarget, err := store.ReadingTargets.GetByID(readingProgress.TargetID)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error get reading target", nil)
+		return
+	}
+
+	// New verses replace the range, a page alone makes it a whole page
+	// reading unless the page is unchanged.
+	if readingProgressUpdate.StartVerse != "" || readingProgressUpdate.EndVerse != "" || readingProgressUpdate.CurrentPage != readingProgress.CurrentPage {
+		readingProgress.CurrentPage = readingProgressUpdate.CurrentPage
+		readingProgress.StartVerse = readingProgressUpdate.StartVerse
+		readingProgress.EndVerse = readingProgressUpdate.EndVerse
+	}
 
-	query := "UPDATE reading_progress SET current_page = $1 WHERE progress_id = $2"
-	_, err = db.GetDB().Exec(query, readingProgress.CurrentPage, readingProgress.ID)
+	verses, err := progressRange(&readingProgress, readingTarget)
 	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	readingProgress.PageShare, err = progressPageShare(readingProgress, verses)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error get reading progress", nil)
+		return
+	}
+	if readingProgress.PageShare == 0 {
+		helpers.ResponseJSON(w, nil, http.StatusConflict, "Page "+ strconv.Itoa(readingProgress.CurrentPage) +"  already read", nil)
+		return
+	}
+
+	err = store.ReadingProgress.Update(readingProgress)
+	if err == storage.ErrConflict {
+		helpers.ResponseJSON(w, err, http.StatusConflict, "Page "+ strconv.Itoa(readingProgress.CurrentPage) +"  already read", nil)
+		return
+	} else if err != nil {
 		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error updating reading progress", nil)
 		return
 	}
+	if readingProgress.Kind != dto.ProgressKindReview {
+		recordKhatam(readingTarget)
+	}
 
 	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", readingProgress)
 }
@@ -88,9 +161,14 @@ func DeleteReadingProgress(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	readingProgressID := vars["id"]
 
+	id, err := strconv.Atoi(readingProgressID)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Invalid reading progress ID", nil)
+		return
+	}
+
 	// Delete the user from the database by ID
-	query := "DELETE FROM reading_progress WHERE progress_id = $1"
-	_, err := db.GetDB().Exec(query, readingProgressID)
+	err = store.ReadingProgress.Delete(id)
 	if err != nil {
 		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error deleting reading progress", nil)
 		return
@@ -123,31 +201,185 @@ func CreateReadingProgress(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if readingProgress.CurrentPage < readingTarget.StartPage || readingProgress.CurrentPage > readingTarget.EndPage {
-		helpers.ResponseJSON(w, err, http.StatusBadRequest, "page is more or less than target", nil)
+	verses, err := progressRange(&readingProgress, readingTarget)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validateReadAt(readingProgress.ReadAt, time.Now()); err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid readAt", nil)
 		return
 	}
 
-	readedPage := getReadedPages(user.ID, targetID)
-	if containsValue(readedPage, readingProgress.CurrentPage) {
-		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Page "+ strconv.Itoa(readingProgress.CurrentPage) +"  already read", nil)
+	if err := validateProgressKind(readingProgress.Kind); err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid kind", nil)
 		return
 	}
 
 	readingProgress.UserID = user.ID
 	readingProgress.TargetID = readingTarget.ID
 
-	query := "INSERT INTO reading_progress (user_id, target_id, current_page) VALUES ($1, $2, $3) RETURNING progress_id"
-	err = db.GetDB().QueryRow(query, readingProgress.UserID, readingProgress.TargetID, readingProgress.CurrentPage).Scan(&readingProgress.ID)
+	readingProgress.PageShare, err = progressPageShare(readingProgress, verses)
 	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error get reading progress", nil)
+		return
+	}
+	if readingProgress.PageShare == 0 && readingProgress.StartVerse != "" {
+		helpers.ResponseJSON(w, nil, http.StatusConflict, "Verses "+readingProgress.StartVerse+" - "+readingProgress.EndVerse+" already read", nil)
+		return
+	}
+
+	// The unique constraint on the page decides, a concurrent request may
+	// have logged it since it was last read. Reviews can repeat a page.
+	if readingProgress.PageShare == 0 {
+		err = storage.ErrConflict
+	} else {
+		err = store.ReadingProgress.Create(&readingProgress)
+	}
+	if err == storage.ErrConflict {
+		existing, _ := getReadingProgressByPage(user.ID, readingTarget.ID, readingProgress.CurrentPage)
+		helpers.ResponseJSON(w, nil, http.StatusConflict, "Page "+ strconv.Itoa(readingProgress.CurrentPage) +"  already read", existing)
+		return
+	} else if err != nil {
 		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error creating reading progress", nil)
 		return
 	}
+	if readingProgress.Kind != dto.ProgressKindReview {
+		recordKhatam(readingTarget)
+	}
 
 	helpers.ResponseJSON(w, err, http.StatusCreated, "SUCCESS", readingProgress)
 	
 }
 
+// CreateBulkReadingProgress logs a page range or a list of pages at once.
+// Pages already read are skipped and reported, the rest are stored together.
+// A review logs every page again.
+func CreateBulkReadingProgress(w http.ResponseWriter, r *http.Request) {
+	var bulkRequest dto.BulkReadingProgressRequest
+	err := json.NewDecoder(r.Body).Decode(&bulkRequest)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	vars := mux.Vars(r)
+	userID := vars["id"]
+	targetID := vars["tid"]
+
+	user, err := getUserByUsername(userID)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Error get user", nil)
+		return
+	}
+
+	readingTarget, err := getReadingTargetByID(targetID)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Error get reading target", nil)
+		return
+	}
+
+	pages := bulkRequest.Pages
+	if len(pages) == 0 {
+		if bulkRequest.StartPage == 0 || bulkRequest.EndPage < bulkRequest.StartPage {
+			helpers.ResponseJSON(w, err, http.StatusBadRequest, "pages or a valid page range is required", nil)
+			return
+		}
+		for page := bulkRequest.StartPage; page <= bulkRequest.EndPage; page++ {
+			pages = append(pages, page)
+		}
+	}
+
+	for _, page := range pages {
+		if page < readingTarget.StartPage || page > readingTarget.EndPage {
+			helpers.ResponseJSON(w, err, http.StatusBadRequest, "page "+strconv.Itoa(page)+" is more or less than target", nil)
+			return
+		}
+	}
+
+	if err := validateReadAt(bulkRequest.ReadAt, time.Now()); err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid readAt", nil)
+		return
+	}
+
+	if err := validateProgressKind(bulkRequest.Kind); err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid kind", nil)
+		return
+	}
+
+	coverage := newPageCoverage(nil)
+	if bulkRequest.Kind != dto.ProgressKindReview {
+		progresses, err := getReadingProgressByUserIDTargetID(user.ID, readingTarget.ID)
+		if err != nil {
+			helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error get reading progress", nil)
+			return
+		}
+		coverage = newPageCoverage(progresses)
+	}
+
+	result := dto.BulkReadingProgressResult{Pages: make([]dto.BulkReadingProgressPage, 0, len(pages))}
+	requested := make(map[int]bool)
+	var readingProgresses []*dto.ReadingProgress
+	for _, page := range pages {
+		status := dto.BulkProgressCreated
+		if requested[page] {
+			status = dto.BulkProgressDuplicate
+		} else if coverage.Complete(page) {
+			status = dto.BulkProgressAlreadyRead
+		}
+		requested[page] = true
+		result.Pages = append(result.Pages, dto.BulkReadingProgressPage{Page: page, Status: status})
+
+		if status != dto.BulkProgressCreated {
+			result.Skipped++
+			continue
+		}
+		verses, _ := quran.GetPageVerses(page)
+		readingProgresses = append(readingProgresses, &dto.ReadingProgress{
+			UserID:      user.ID,
+			TargetID:    readingTarget.ID,
+			CurrentPage: page,
+			ReadAt:      bulkRequest.ReadAt,
+			Kind:        bulkRequest.Kind,
+			PageShare:   coverage.UncoveredShare(verses),
+		})
+	}
+
+	err = store.ReadingProgress.CreateBatch(readingProgresses)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error creating reading progress", nil)
+		return
+	}
+
+	// Pages logged by a concurrent request come back without an ID
+	created, inserted := 0, 0
+	for i := range result.Pages {
+		if result.Pages[i].Status != dto.BulkProgressCreated {
+			continue
+		}
+		readingProgress := readingProgresses[inserted]
+		inserted++
+		if readingProgress.ID == 0 {
+			result.Pages[i].Status = dto.BulkProgressAlreadyRead
+			result.Skipped++
+			continue
+		}
+		result.Pages[i].ProgressID = readingProgress.ID
+		created++
+	}
+	result.Created = created
+	if created > 0 && bulkRequest.Kind != dto.ProgressKindReview {
+		recordKhatam(readingTarget)
+	}
+
+	statusCode := http.StatusCreated
+	if created == 0 {
+		statusCode = http.StatusOK
+	}
+	helpers.ResponseJSON(w, err, statusCode, "SUCCESS", result)
+}
+
 func GetAllReadingProgressByUserID(w http.ResponseWriter, r *http.Request) {
 	// Query all reading_targets from the database
 	vars := mux.Vars(r)
@@ -159,35 +391,25 @@ func GetAllReadingProgressByUserID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	query := "SELECT * FROM reading_progress where user_id = $1 ORDER BY last_update_timestamp ASC"
-	rows, err := db.GetDB().Query(query, user.ID)
+	readingProgresses, err := store.ReadingProgress.GetByUserID(user.ID)
 	if err != nil {
 		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching get all reading progress", nil)
 		return
 	}
-	defer rows.Close()
 
-	var readingProgresses []dto.ReadingProgress
 	readingProgressSorted := make(map[int]map[string][]dto.ReadingProgress)
-	for rows.Next() {
-		var readingProgress dto.ReadingProgress
-		err := rows.Scan(&readingProgress.ID, &readingProgress.UserID, &readingProgress.TargetID, &readingProgress.CurrentPage, &readingProgress.TimeStamp)
-		if err != nil {
-			helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error scanning all reading progress by userID", nil)
-			return
+	for _, readingProgress := range readingProgresses {
+		if readingProgressSorted[readingProgress.ReadAt.Year()] == nil {
+			readingProgressSorted[readingProgress.ReadAt.Year()] = make(map[string][]dto.ReadingProgress)
 		}
-		if readingProgressSorted[readingProgress.TimeStamp.Year()] == nil {
-			readingProgressSorted[readingProgress.TimeStamp.Year()] = make(map[string][]dto.ReadingProgress)
-		}
-		if readingProgressSorted[readingProgress.TimeStamp.Year()][readingProgress.TimeStamp.Month().String()] == nil {
-			readingProgressSorted[readingProgress.TimeStamp.Year()][readingProgress.TimeStamp.Month().String()] = []dto.ReadingProgress{}
+		if readingProgressSorted[readingProgress.ReadAt.Year()][readingProgress.ReadAt.Month().String()] == nil {
+			readingProgressSorted[readingProgress.ReadAt.Year()][readingProgress.ReadAt.Month().String()] = []dto.ReadingProgress{}
 		}
 		
-		readingProgressSorted[readingProgress.TimeStamp.Year()][readingProgress.TimeStamp.Month().String()] = append(
-			readingProgressSorted[readingProgress.TimeStamp.Year()][readingProgress.TimeStamp.Month().String()],
+		readingProgressSorted[readingProgress.ReadAt.Year()][readingProgress.ReadAt.Month().String()] = append(
+			readingProgressSorted[readingProgress.ReadAt.Year()][readingProgress.ReadAt.Month().String()],
 			readingProgress,
 		)
-		readingProgresses = append(readingProgresses, readingProgress)
 	}
 
 	response := dto.ReadingProgressAggregated{
@@ -227,13 +449,14 @@ func GetAllReadingProgressByUserIDTargetID(w http.ResponseWriter, r *http.Reques
 
 // getReadingProgressByID retrieves reading_progress data from the database by ID.
 func getReadingProgressByID(readingProgressID string) (dto.ReadingProgress, error) {
-	// Query readingProgress data from the database by ID
-	query := "SELECT * FROM reading_progress WHERE progress_id = $1"
-	row := db.GetDB().QueryRow(query, readingProgressID)
+	id, err := strconv.Atoi(readingProgressID)
+	if err != nil {
+		return dto.ReadingProgress{}, fmt.Errorf("Reading Target with ID %s not found", readingProgressID)
+	}
 
-	var readingProgress dto.ReadingProgress
-	err := row.Scan(&readingProgress.ID, &readingProgress.UserID, &readingProgress.TargetID, &readingProgress.CurrentPage, &readingProgress.TimeStamp)
-	if err == sql.ErrNoRows {
+	// Query readingProgress data from the database by ID
+	readingProgress, err := store.ReadingProgress.GetByID(id)
+	if err == storage.ErrNotFound {
 		return dto.ReadingProgress{}, fmt.Errorf("Reading Target with ID %s not found", readingProgressID)
 	} else if err != nil {
 		log.Printf("Error : %v", err.Error())
@@ -243,88 +466,81 @@ func getReadingProgressByID(readingProgressID string) (dto.ReadingProgress, erro
 	return readingProgress, nil
 }
 
-func getReadedPages(userID int, targetID string) []int {
-	readedPages := make([]int, 0)
+// progressRange checks that an entry lies within its target and returns
+// the verses it covers. A verse range starts on the page of its first verse.
+func progressRange(readingProgress *dto.ReadingProgress, readingTarget dto.ReadingTarget) ([]quran.Verse, error) {
+	if (readingProgress.StartVerse == "") != (readingProgress.EndVerse == "") {
+		return nil, errors.New("startVerse and endVerse must be given together")
+	}
+	if readingProgress.StartVerse != "" {
+		startVerse, err := quran.GetVerseByKey(readingProgress.StartVerse)
+		if err != nil {
+			return nil, errors.New("invalid verse range")
+		}
+		readingProgress.CurrentPage = startVerse.Page
+	}
 
-	targetIDConv, _ := strconv.Atoi(targetID)
-	readingProgress, err := getReadingProgressByUserIDTargetID(userID, targetIDConv)
+	if readingProgress.CurrentPage < readingTarget.StartPage || progressLastPage(*readingProgress) > readingTarget.EndPage {
+		return nil, errors.New("page is more or less than target")
+	}
 
+	verses, err := progressVerses(*readingProgress)
 	if err != nil {
-		log.Printf("Error : %v", err.Error())
-		return readedPages
+		return nil, errors.New("invalid verse range")
 	}
+	return verses, nil
+}
 
-	for _, v := range readingProgress {
-		readedPages = append(readedPages, v.CurrentPage)
+// progressPageShare returns the share of verses no other first reading of
+// the target has covered. Reviews count in full.
+func progressPageShare(readingProgress dto.ReadingProgress, verses []quran.Verse) (float64, error) {
+	if readingProgress.Kind == dto.ProgressKindReview {
+		return newPageCoverage(nil).UncoveredShare(verses), nil
 	}
-
-	return readedPages
+	progresses, err := getReadingProgressByUserIDTargetID(readingProgress.UserID, readingProgress.TargetID)
+	if err != nil {
+		return 0, err
+	}
+	others := progresses[:0]
+	for _, progress := range progresses {
+		if progress.ID != readingProgress.ID {
+			others = append(others, progress)
+		}
+	}
+	return newPageCoverage(others).UncoveredShare(verses), nil
 }
 
-func containsValue(slice []int, value int) bool {
-	for _, element := range slice {
-		if element == value {
-			return true
+// getReadingProgressByPage returns the first reading of page in a target.
+func getReadingProgressByPage(userID, targetID, page int) (dto.ReadingProgress, error) {
+	readingProgresss, err := getReadingProgressByUserIDTargetID(userID, targetID)
+	if err != nil {
+		return dto.ReadingProgress{}, err
+	}
+
+	for _, readingProgress := range firstReadings(readingProgresss) {
+		if readingProgress.CurrentPage == page {
+			return readingProgress, nil
 		}
 	}
-	return false
+	return dto.ReadingProgress{}, storage.ErrNotFound
 }
 
 func getReadingProgressByUserIDTargetID(userID, targetID int) ([]dto.ReadingProgress, error) {
-
-	query := "SELECT * FROM reading_progress WHERE user_id = $1 AND target_id = $2 ORDER BY last_update_timestamp DESC"
-	rows, err := db.GetDB().Query(query, userID, targetID)
+	readingProgresss, err := store.ReadingProgress.GetByUserIDTargetID(userID, targetID)
 	if err != nil {
 		log.Printf("Error : %v", err.Error())
 		return []dto.ReadingProgress{}, err
 	}
-	defer rows.Close()
-
-	var readingProgresss []dto.ReadingProgress
-	for rows.Next() {
-		var readingProgress dto.ReadingProgress
-		err := rows.Scan(&readingProgress.ID, &readingProgress.UserID, &readingProgress.TargetID, &readingProgress.CurrentPage, &readingProgress.TimeStamp)
-		if err != nil {
-			log.Printf("Error : %v", err.Error())
-			return []dto.ReadingProgress{}, err
-		}
-		readingProgresss = append(readingProgresss, readingProgress)
-	}
 
 	return readingProgresss, nil
 }
 
 func getReadingProgressByTargetIDsAndTimeRange(targetIDs []int, startTime, endTime time.Time) ([]dto.ReadingProgress, error) {
-    inClause := helpers.BuildInClause(targetIDs)
-
-	if len(inClause) == 0 {
-		return []dto.ReadingProgress{}, errors.New("empty list of target IDs")
-	}
-	query := fmt.Sprintf(`
-        SELECT * FROM reading_progress
-        WHERE target_id IN (%s)
-        AND last_update_timestamp >= $1 AND last_update_timestamp <= $2
-    `, inClause)
-
-	fmt.Println(startTime.String())
-
-    rows, err := db.GetDB().Query(query, startTime, endTime)
-    if err != nil {
-        log.Printf("Error: %v", err.Error())
-        return []dto.ReadingProgress{}, err
-    }
-    defer rows.Close()
-
-    var readingProgresss []dto.ReadingProgress
-    for rows.Next() {
-        var readingProgress dto.ReadingProgress
-        err := rows.Scan(&readingProgress.ID, &readingProgress.UserID, &readingProgress.TargetID, &readingProgress.CurrentPage, &readingProgress.TimeStamp)
-        if err != nil {
-            log.Printf("Error: %v", err.Error())
-            return []dto.ReadingProgress{}, err
-        }
-        readingProgresss = append(readingProgresss, readingProgress)
-    }
-
-    return readingProgresss, nil
+	readingProgresss, err := store.ReadingProgress.GetByTargetIDsAndTimeRange(targetIDs, startTime, endTime)
+	if err != nil {
+		log.Printf("Error: %v", err.Error())
+		return []dto.ReadingProgress{}, err
+	}
+
+	return readingProgresss, nil
 }
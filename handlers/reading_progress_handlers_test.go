@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/dto"
+)
+
+func TestCreateReadingProgress(t *testing.T) {
+	newTestStore(t)
+	user := newTestUser(t, "fulan", "")
+	readingTarget := newTestTarget(t, dto.ReadingTarget{UserID: user.ID, StartDate: "2026-10-01", EndDate: "2026-10-30", StartPage: 1, EndPage: 20, Pages: 20})
+	vars := map[string]string{"id": user.Username, "tid": strconv.Itoa(readingTarget.ID)}
+
+	tests := []struct {
+		name   string
+		body   dto.ReadingProgress
+		status int
+	}{
+		{"new page", dto.ReadingProgress{CurrentPage: 3}, http.StatusCreated},
+		{"page already read", dto.ReadingProgress{CurrentPage: 3}, http.StatusConflict},
+		{"page after the target", dto.ReadingProgress{CurrentPage: 21}, http.StatusBadRequest},
+		{"verse range", dto.ReadingProgress{StartVerse: "2:1", EndVerse: "2:5"}, http.StatusCreated},
+		{"verses already read", dto.ReadingProgress{StartVerse: "2:1", EndVerse: "2:5"}, http.StatusConflict},
+		{"start verse alone", dto.ReadingProgress{StartVerse: "2:6"}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := serve(t, CreateReadingProgress, http.MethodPost, vars, tt.body, nil)
+			if response.Code != tt.status {
+				t.Errorf("status = %d, want %d (%v)", response.Code, tt.status, response.Message)
+			}
+		})
+	}
+}
+
+func TestUpdateReadingProgressByID(t *testing.T) {
+	newTestStore(t)
+	user := newTestUser(t, "fulan", "")
+	readingTarget := newTestTarget(t, dto.ReadingTarget{UserID: user.ID, StartDate: "2026-10-01", EndDate: "2026-10-30", StartPage: 1, EndPage: 20, Pages: 20})
+	vars := map[string]string{"id": user.Username, "tid": strconv.Itoa(readingTarget.ID)}
+
+	var first, second dto.ReadingProgress
+	serve(t, CreateReadingProgress, http.MethodPost, vars, dto.ReadingProgress{CurrentPage: 3}, &first)
+	serve(t, CreateReadingProgress, http.MethodPost, vars, dto.ReadingProgress{CurrentPage: 4}, &second)
+	progressVars := map[string]string{"id": strconv.Itoa(second.ID)}
+
+	response := serve(t, UpdateReadingProgressByID, http.MethodPut, progressVars, dto.ReadingProgress{CurrentPage: first.CurrentPage}, nil)
+	if response.Code != http.StatusConflict {
+		t.Errorf("moving onto a read page: status = %d, want %d", response.Code, http.StatusConflict)
+	}
+
+	response = serve(t, UpdateReadingProgressByID, http.MethodPut, progressVars, dto.ReadingProgress{CurrentPage: 25}, nil)
+	if response.Code != http.StatusBadRequest {
+		t.Errorf("moving out of the target: status = %d, want %d", response.Code, http.StatusBadRequest)
+	}
+
+	var updated dto.ReadingProgress
+	response = serve(t, UpdateReadingProgressByID, http.MethodPut, progressVars, dto.ReadingProgress{StartVerse: "2:1", EndVerse: "2:3"}, &updated)
+	if response.Code != http.StatusOK {
+		t.Fatalf("moving to a verse range: status = %d, want %d (%v)", response.Code, http.StatusOK, response.Message)
+	}
+	if updated.CurrentPage != 2 || updated.PageShare <= 0 || updated.PageShare >= 1 {
+		t.Errorf("verse range = page %d share %v, want page 2 and part of it", updated.CurrentPage, updated.PageShare)
+	}
+
+	stored, err := store.ReadingProgress.GetByID(second.ID)
+	if err != nil {
+		t.Fatalf("get progress: %v", err)
+	}
+	if stored.StartVerse != "2:1" || stored.EndVerse != "2:3" || stored.PageShare != updated.PageShare {
+		t.Errorf("stored = %s-%s share %v, want 2:1-2:3 share %v", stored.StartVerse, stored.EndVerse, stored.PageShare, updated.PageShare)
+	}
+}
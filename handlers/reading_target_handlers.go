@@ -1,20 +1,19 @@
 package handlers
 
 import (
-	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
-	"github.com/daffashafwan/tadarus-yuk/db"
-	externalDto "github.com/daffashafwan/tadarus-yuk/external/dto"
-	"github.com/daffashafwan/tadarus-yuk/internal/authorization"
 	"github.com/daffashafwan/tadarus-yuk/internal/dto"
 	"github.com/daffashafwan/tadarus-yuk/internal/helpers"
+	"github.com/daffashafwan/tadarus-yuk/internal/storage"
 	"github.com/gorilla/mux"
 )
 
@@ -24,24 +23,11 @@ const (
 
 func GetAllReadingTarget(w http.ResponseWriter, r *http.Request) {
 	// Query all reading_targets from the database
-	query := "SELECT * FROM reading_target"
-	rows, err := db.GetDB().Query(query)
+	readingTargets, err := store.ReadingTargets.GetAll()
 	if err != nil {
 		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching get all reading target", nil)
 		return
 	}
-	defer rows.Close()
-
-	var readingTargets []dto.ReadingTarget
-	for rows.Next() {
-		var readingTarget dto.ReadingTarget
-		err := rows.Scan(&readingTarget.ID, &readingTarget.UserID, &readingTarget.StartDate, &readingTarget.EndDate, &readingTarget.Pages, &readingTarget.Name, &readingTarget.StartPage, &readingTarget.EndPage, &readingTarget.GoogleCalendarID, &readingTarget.IsPublic)
-		if err != nil {
-			helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching reading target rows", nil)
-			return
-		}
-		readingTargets = append(readingTargets, readingTarget)
-	}
 
 	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", readingTargets)
 }
@@ -56,6 +42,7 @@ func GetReadingTargetByID(w http.ResponseWriter, r *http.Request) {
 		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching get reading target by ID", nil)
 		return
 	}
+	readingTargetRes.Status = targetStatus(readingTargetRes, localDay(time.Now(), targetLocation(readingTargetRes.UserID)))
 
 	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", readingTargetRes)
 }
@@ -77,66 +64,49 @@ func UpdateReadingTargetByID(w http.ResponseWriter, r *http.Request) {
 		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Invalid request body", nil)
 		return
 	}
+	if err := resolveHijriDates(&readingTargetUpdate); err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid hijri date", nil)
+		return
+	}
 
 	readingTarget.Name = readingTargetUpdate.Name
 	readingTarget.StartDate = readingTargetUpdate.StartDate
 	readingTarget.EndDate = readingTargetUpdate.EndDate
 	readingTarget.Pages = readingTargetUpdate.Pages
+	readingTarget.Recurrence = readingTargetUpdate.Recurrence
+	readingTarget.CarryOver = readingTargetUpdate.CarryOver
+	if !isValidRecurrence(readingTarget.Recurrence) {
+		helpers.ResponseJSON(w, nil, http.StatusBadRequest, "recurrence must be weekly, monthly or ramadan", nil)
+		return
+	}
 	if readingTarget.IsPublic != readingTargetUpdate.IsPublic {
 		isPublicChanged = true
 	}
 	readingTarget.IsPublic = readingTargetUpdate.IsPublic
 
-	err = updateReadingTarget(readingTarget)
-	if err != nil {
-		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error updating reading target", nil)
-		return
-	}
-
 	if isPublicChanged {
+		err = updateReadingTarget(readingTarget)
+		if err != nil {
+			helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error updating reading target", nil)
+			return
+		}
 		helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", readingTarget)
 		return
 	}
 
-	userID, err := authorization.EncryptUserID(readingTarget.UserID)
+	// The calendar event is pushed by the calendar sync worker
+	err = store.ReadingTargets.UpdateWithCalendarSync(readingTarget)
 	if err != nil {
 		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error updating reading target", nil)
 		return
 	}
-
-	user, err := getUserByID(userID)
-	if err != nil {
-		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error get user, update reading target", nil)
-		return
-	}
-
-	event, err := pushCalendarEvent(user.GoogleToken, externalDto.CalendarEvent{
-		GoogleCalendarID: readingTarget.GoogleCalendarID,
-		EventName:        readingTarget.Name,
-		EventDescription: "Membaca Halaman " + strconv.Itoa(readingTarget.StartPage) + " sampai " + strconv.Itoa(readingTarget.EndPage),
-		StartDate:        readingTarget.StartDate,
-		EndDate:          readingTarget.EndDate,
-		Type:             "EDIT",
-	})
-	if err != nil {
-		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error updating reading target calendar", nil)
-		return
-	}
-
-	readingTarget.GoogleCalendarID = event.Id
-	err = updateReadingTarget(readingTarget)
-	if err != nil {
-		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error update, updating reading target calendar google id", nil)
-		return
-	}
+	readingTarget.CalendarSync.Status = dto.CalendarSyncPending
 
 	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", readingTarget)
 }
 
 func updateReadingTarget(readingTarget dto.ReadingTarget) error {
-	query := "UPDATE reading_target SET name = $1, start_date = $2, end_date = $3, start_page = $4, end_page = $5, target_pages_per_interval = $6, google_calendar_id = $7, is_public = $8 WHERE target_id = $9"
-	_, err := db.GetDB().Exec(query, readingTarget.Name, readingTarget.StartDate, readingTarget.EndDate, readingTarget.StartPage, readingTarget.EndPage, readingTarget.Pages, readingTarget.GoogleCalendarID, readingTarget.IsPublic, readingTarget.ID)
-	return err
+	return store.ReadingTargets.Update(readingTarget)
 }
 
 func DeleteReadingTarget(w http.ResponseWriter, r *http.Request) {
@@ -149,39 +119,13 @@ func DeleteReadingTarget(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userID, err := authorization.EncryptUserID(readingTarget.UserID)
-	if err != nil {
-		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error deleting reading target", nil)
-		return
-	}
-
-	user, err := getUserByID(userID)
-	if err != nil {
-		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error get user, delet reading target", nil)
-		return
-	}
-
-	// Delete the user from the database by ID
-	query := "DELETE FROM reading_target WHERE target_id = $1"
-	_, err = db.GetDB().Exec(query, readingTargetID)
+	// Delete the reading target, the calendar sync worker removes its event
+	err = store.ReadingTargets.DeleteWithCalendarSync(readingTarget)
 	if err != nil {
 		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error deleting reading target", nil)
 		return
 	}
 
-	_, err = pushCalendarEvent(user.GoogleToken, externalDto.CalendarEvent{
-		GoogleCalendarID: readingTarget.GoogleCalendarID,
-		EventName:        "",
-		EventDescription: "",
-		StartDate:        "2006-01-02",
-		EndDate:          "2006-01-02",
-		Type:             "DELETE",
-	})
-	if err != nil {
-		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error delete, updating reading target calendar", nil)
-		return
-	}
-
 	helpers.ResponseJSON(w, err, http.StatusNoContent, "SUCCESS", nil)
 }
 
@@ -202,39 +146,42 @@ func CreateReadingTargetByUserID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := resolveHijriDates(&readingTarget); err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid hijri date", nil)
+		return
+	}
+
 	if !isValidDateRange(readingTarget.StartDate, readingTarget.EndDate) {
 		helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid date or date range", nil)
 		return
 	}
 
-	if readingTarget.Pages < 1 || readingTarget.Pages > PagesAlQuran {
-		helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid number of pages", nil)
+	if err := resolveTargetScope(&readingTarget); err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid scope", nil)
 		return
 	}
 
-	query := "INSERT INTO reading_target (user_id, name, start_date, end_date, start_page, end_page, target_pages_per_interval, google_calendar_id, is_public) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING target_id"
-	err = db.GetDB().QueryRow(query, user.ID, readingTarget.Name, readingTarget.StartDate, readingTarget.EndDate, readingTarget.StartPage, readingTarget.EndPage, readingTarget.Pages, readingTarget.GoogleCalendarID, readingTarget.IsPublic).Scan(&readingTarget.ID)
-	if err != nil {
-		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error creating reading target", nil)
+	if readingTarget.Pages < 1 || readingTarget.Pages > PagesAlQuran {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid number of pages", nil)
 		return
 	}
 
-	event, err := pushCalendarEvent(user.GoogleToken, externalDto.CalendarEvent{
-		EventName:        readingTarget.Name,
-		EventDescription: "Membaca Halaman " + strconv.Itoa(readingTarget.StartPage) + " sampai " + strconv.Itoa(readingTarget.EndPage),
-		StartDate:        readingTarget.StartDate,
-		EndDate:          readingTarget.EndDate,
-		Type:             "ADD",
-	})
-	if err != nil {
-		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error creating reading target calendar", nil)
+	if !isValidRecurrence(readingTarget.Recurrence) {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "recurrence must be weekly, monthly or ramadan", nil)
 		return
 	}
 
-	readingTarget.GoogleCalendarID = event.Id
-	err = updateReadingTarget(readingTarget)
+	// The calendar event is pushed by the calendar sync worker
+	readingTarget.UserID = user.ID
+	readingTarget.GoogleCalendarID = ""
+	readingTarget.Status = dto.TargetStatusActive
+	readingTarget.PausedAt = nil
+	readingTarget.PausedDays = 0
+	readingTarget.PreviousID = nil
+	readingTarget.NextID = nil
+	err = store.ReadingTargets.CreateWithCalendarSync(&readingTarget)
 	if err != nil {
-		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error updating reading target calendar google id", nil)
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error creating reading target", nil)
 		return
 	}
 
@@ -253,53 +200,204 @@ func GetAllReadingTargetByUserID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	//query := "SELECT * FROM reading_target where user_id = $1"
-	query := `
-        SELECT *
-        FROM reading_target
-        WHERE user_id = $1;
-    `
-	rows, err := db.GetDB().Query(query, user.ID)
+	statuses, err := parseTargetStatuses(r.URL.Query().Get("status"))
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid status", nil)
+		return
+	}
+
+	location, err := userLocation(user.Timezone)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error loading user timezone", nil)
+		return
+	}
+	today := localDay(time.Now(), location)
+
+	userTargets, err := store.ReadingTargets.GetByUserID(user.ID)
 	if err != nil {
 		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching get reading target by userID", nil)
 		return
 	}
-	defer rows.Close()
 
 	var readingTargets []dto.ReadingTarget
-	for rows.Next() {
-		var readingTarget dto.ReadingTarget
-		err := rows.Scan(&readingTarget.ID, &readingTarget.UserID, &readingTarget.StartDate, &readingTarget.EndDate, &readingTarget.Pages, &readingTarget.Name, &readingTarget.StartPage, &readingTarget.EndPage, &readingTarget.GoogleCalendarID, &readingTarget.IsPublic)
-		if err != nil {
-			helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error scanning reading target rows", nil)
-			return
+	for _, readingTarget := range userTargets {
+		readingTarget.Status = targetStatus(readingTarget, today)
+		if len(statuses) > 0 && !statuses[readingTarget.Status] {
+			continue
 		}
 		progresses, err := getReadingProgressByUserIDTargetID(readingTarget.UserID, readingTarget.ID)
 		if err != nil {
 			helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error getting reading progress in target", nil)
 			return
 		}
+		coverage := newPageCoverage(progresses)
+		progresses = firstReadings(progresses)
 		readingTarget.LastReadPage = 0
 		if len(progresses) > 0 {
-			readingTarget.LastReadPage = progresses[0].CurrentPage
+			readingTarget.LastReadPage = progressLastPage(progresses[0])
 		}
-		readingTarget.Progress = float64(len(progresses)) / readingTarget.Pages * 100
+		// Pages is set by the user and can be less than the page range
+		readingTarget.Progress = math.Min(coverage.PagesRead(readingTarget.StartPage, readingTarget.EndPage)/readingTarget.Pages*100, 100)
 		readingTarget.Progress = float64(int(readingTarget.Progress*10)) / 10
+		if completedAt, completed := coverage.CompletedAt(readingTarget.StartPage, readingTarget.EndPage); completed {
+			readingTarget.CompletedAt = &completedAt
+		}
 		readingTargets = append(readingTargets, readingTarget)
 	}
 
 	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", readingTargets)
 }
 
+// PauseReadingTarget pauses an active target, for travel or illness. The
+// days it stays paused are added to its end date when it is resumed.
+func PauseReadingTarget(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	readingTarget, err := getReadingTargetByID(vars["id"])
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching get reading target by ID", nil)
+		return
+	}
+
+	// paused_at is kept in UTC like the other timestamps
+	now := time.Now().UTC()
+	status := targetStatus(readingTarget, localDay(now, targetLocation(readingTarget.UserID)))
+	if status != dto.TargetStatusActive {
+		helpers.ResponseJSON(w, nil, http.StatusConflict, "only an active target can be paused, this one is "+status, nil)
+		return
+	}
+
+	readingTarget.Status = dto.TargetStatusPaused
+	readingTarget.PausedAt = &now
+	err = updateReadingTarget(readingTarget)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error updating reading target", nil)
+		return
+	}
+
+	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", readingTarget)
+}
+
+// ResumeReadingTarget makes a paused target active again and moves its end
+// date by the number of days it was paused.
+func ResumeReadingTarget(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	readingTarget, err := getReadingTargetByID(vars["id"])
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching get reading target by ID", nil)
+		return
+	}
+
+	if readingTarget.Status != dto.TargetStatusPaused {
+		helpers.ResponseJSON(w, nil, http.StatusConflict, "the target is not paused", nil)
+		return
+	}
+
+	now := time.Now()
+	location := targetLocation(readingTarget.UserID)
+	pausedDays := 0
+	if readingTarget.PausedAt != nil {
+		pausedDays = daysBetween(localDay(*readingTarget.PausedAt, location), localDay(now, location))
+	}
+	if pausedDays < 0 {
+		pausedDays = 0
+	}
+
+	endDate, err := time.Parse("2006-01-02", dateOnly(readingTarget.EndDate))
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error reading target end date", nil)
+		return
+	}
+	readingTarget.EndDate = endDate.AddDate(0, 0, pausedDays).Format("2006-01-02")
+	readingTarget.PausedDays += pausedDays
+	readingTarget.PausedAt = nil
+	readingTarget.Status = dto.TargetStatusActive
+
+	if pausedDays == 0 {
+		err = updateReadingTarget(readingTarget)
+	} else {
+		// The calendar event is moved by the calendar sync worker
+		err = store.ReadingTargets.UpdateWithCalendarSync(readingTarget)
+		readingTarget.CalendarSync.Status = dto.CalendarSyncPending
+	}
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error updating reading target", nil)
+		return
+	}
+	readingTarget.Status = targetStatus(readingTarget, localDay(now, location))
+
+	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", readingTarget)
+}
+
+// targetStatus returns the state of a target on today, a day from localDay.
+// An active target whose end date has passed is expired.
+func targetStatus(readingTarget dto.ReadingTarget, today time.Time) string {
+	switch readingTarget.Status {
+	case dto.TargetStatusPaused, dto.TargetStatusCompleted, dto.TargetStatusReassigned:
+		return readingTarget.Status
+	}
+	endDate, err := time.Parse("2006-01-02", dateOnly(readingTarget.EndDate))
+	if err == nil && today.After(endDate) {
+		return dto.TargetStatusExpired
+	}
+	return dto.TargetStatusActive
+}
+
+// targetLocation returns the timezone of the target owner, UTC when it
+// can not be loaded.
+func targetLocation(userID int) *time.Location {
+	var timezone string
+	if user, err := getUserByIDWithoutEncrypt(userID); err == nil {
+		timezone = user.Timezone
+	}
+	location, err := userLocation(timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// targetLocations remembers the owner timezones loaded while going through
+// many targets, so each owner is looked up once.
+type targetLocations map[int]*time.Location
+
+func (locations targetLocations) get(userID int) *time.Location {
+	location, ok := locations[userID]
+	if !ok {
+		location = targetLocation(userID)
+		locations[userID] = location
+	}
+	return location
+}
+
+// parseTargetStatuses reads a comma separated list of states, empty means
+// every state.
+func parseTargetStatuses(value string) (map[string]bool, error) {
+	statuses := make(map[string]bool)
+	if value == "" {
+		return statuses, nil
+	}
+	for _, status := range strings.Split(value, ",") {
+		status = strings.TrimSpace(status)
+		switch status {
+		case dto.TargetStatusActive, dto.TargetStatusPaused, dto.TargetStatusCompleted, dto.TargetStatusExpired, dto.TargetStatusReassigned:
+			statuses[status] = true
+		default:
+			return nil, fmt.Errorf("status must be %s, %s, %s, %s or %s", dto.TargetStatusActive, dto.TargetStatusPaused, dto.TargetStatusCompleted, dto.TargetStatusExpired, dto.TargetStatusReassigned)
+		}
+	}
+	return statuses, nil
+}
+
 // getReadingTargetByID retrieves reading_target data from the database by ID.
 func getReadingTargetByID(readingTargetID string) (dto.ReadingTarget, error) {
-	// Query readingTarget data from the database by ID
-	query := "SELECT * FROM reading_target WHERE target_id = $1"
-	row := db.GetDB().QueryRow(query, readingTargetID)
+	id, err := strconv.Atoi(readingTargetID)
+	if err != nil {
+		return dto.ReadingTarget{}, fmt.Errorf("Reading Target with ID %s not found", readingTargetID)
+	}
 
-	var readingTarget dto.ReadingTarget
-	err := row.Scan(&readingTarget.ID, &readingTarget.UserID, &readingTarget.StartDate, &readingTarget.EndDate, &readingTarget.Pages, &readingTarget.Name, &readingTarget.StartPage, &readingTarget.EndPage, &readingTarget.GoogleCalendarID, &readingTarget.IsPublic)
-	if err == sql.ErrNoRows {
+	// Query readingTarget data from the database by ID
+	readingTarget, err := store.ReadingTargets.GetByID(id)
+	if err == storage.ErrNotFound {
 		return dto.ReadingTarget{}, fmt.Errorf("Reading Target with ID %s not found", readingTargetID)
 	} else if err != nil {
 		log.Printf("Error : %v", err.Error())
@@ -329,23 +427,23 @@ func isValidDateRange(startDateStr, endDateStr string) bool {
 func getAllPublicReadingTarget(userID int) ([]int, []dto.ReadingTarget, error) {
 	// Query readingTarget data from the database by ID
 	var isEligible bool
-	query := "SELECT * FROM reading_target WHERE is_public = $1"
-	rows, err := db.GetDB().Query(query, true)
+	publicTargets, err := store.ReadingTargets.GetPublic()
 	if err != nil {
 		return []int{}, []dto.ReadingTarget{}, err
 	}
-	defer rows.Close()
 
-	var readingTargets []dto.ReadingTarget
+	// Paused and expired targets are left out of the leaderboard
+	now := time.Now()
+	locations := targetLocations{}
 	var ids []int
-	for rows.Next() {
-		var readingTarget dto.ReadingTarget
-		err := rows.Scan(&readingTarget.ID, &readingTarget.UserID, &readingTarget.StartDate, &readingTarget.EndDate, &readingTarget.Pages, &readingTarget.Name, &readingTarget.StartPage, &readingTarget.EndPage, &readingTarget.GoogleCalendarID, &readingTarget.IsPublic)
-		if err != nil {
-			return []int{}, []dto.ReadingTarget{}, err
+	var readingTargets []dto.ReadingTarget
+	for _, readingTarget := range publicTargets {
+		readingTarget.Status = targetStatus(readingTarget, localDay(now, locations.get(readingTarget.UserID)))
+		if readingTarget.Status != dto.TargetStatusActive && readingTarget.Status != dto.TargetStatusCompleted {
+			continue
 		}
-		ids = append(ids, readingTarget.ID)
 		readingTargets = append(readingTargets, readingTarget)
+		ids = append(ids, readingTarget.ID)
 		if readingTarget.UserID == userID {
 			isEligible = true
 		}
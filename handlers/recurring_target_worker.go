@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/dto"
+	"github.com/daffashafwan/tadarus-yuk/internal/hijri"
+	"github.com/daffashafwan/tadarus-yuk/internal/storage"
+)
+
+var recurringTargetInterval = time.Hour
+
+// InitRecurringTargets reads the recurring target scheduler settings from the
+// environment.
+func InitRecurringTargets() {
+	if val, err := strconv.Atoi(os.Getenv("RECURRING_TARGET_INTERVAL")); err == nil && val > 0 {
+		recurringTargetInterval = time.Duration(val) * time.Millisecond
+	}
+}
+
+// RunRecurringTargetWorker creates the next instance of every recurring target
+// whose end date has passed until the context is cancelled.
+func RunRecurringTargetWorker(ctx context.Context) {
+	ticker := time.NewTicker(recurringTargetInterval)
+	defer ticker.Stop()
+
+	for {
+		processRecurringTargets(time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func processRecurringTargets(now time.Time) {
+	readingTargets, err := store.ReadingTargets.GetRecurring()
+	if err != nil {
+		log.Printf("[recurringTarget] error get targets : %v", err.Error())
+		return
+	}
+
+	locations := targetLocations{}
+	for _, readingTarget := range readingTargets {
+		if err := recurTarget(readingTarget, localDay(now, locations.get(readingTarget.UserID))); err != nil {
+			log.Printf("[recurringTarget] error recur target %d : %v", readingTarget.ID, err.Error())
+		}
+	}
+}
+
+// recurTarget creates the next instance of a target once its end date is
+// before today, the current day in the owner's timezone. A paused target
+// waits for its new end date. With CarryOver the pages left unread get their
+// own target next to the new instance.
+func recurTarget(readingTarget dto.ReadingTarget, today time.Time) error {
+	if readingTarget.Status == dto.TargetStatusPaused {
+		return nil
+	}
+	endDate, err := time.Parse("2006-01-02", dateOnly(readingTarget.EndDate))
+	if err != nil {
+		return err
+	}
+	if !today.After(endDate) {
+		return nil
+	}
+
+	next, err := nextTargetInstance(readingTarget, today)
+	if err != nil {
+		return err
+	}
+	readingTargets := []*dto.ReadingTarget{&next}
+
+	if readingTarget.CarryOver {
+		carried, ok, err := carryOverTarget(readingTarget, next)
+		if err != nil {
+			return err
+		}
+		if ok {
+			readingTargets = append(readingTargets, &carried)
+		}
+	}
+
+	// The calendar events are pushed by the calendar sync worker
+	err = store.ReadingTargets.CreateNext(readingTarget, readingTargets)
+	if err == storage.ErrConflict {
+		return nil
+	}
+	return err
+}
+
+// nextTargetInstance moves the dates of a target by its recurrence until
+// they reach today, so periods missed while the scheduler was down are
+// skipped. The days the target was paused are not carried to the next one.
+func nextTargetInstance(readingTarget dto.ReadingTarget, today time.Time) (dto.ReadingTarget, error) {
+	startDate, err := time.Parse("2006-01-02", dateOnly(readingTarget.StartDate))
+	if err != nil {
+		return dto.ReadingTarget{}, err
+	}
+	endDate, err := time.Parse("2006-01-02", dateOnly(readingTarget.EndDate))
+	if err != nil {
+		return dto.ReadingTarget{}, err
+	}
+	endDate = endDate.AddDate(0, 0, -readingTarget.PausedDays)
+
+	for endDate.Before(today) {
+		switch readingTarget.Recurrence {
+		case dto.RecurrenceWeekly:
+			startDate, endDate = startDate.AddDate(0, 0, 7), endDate.AddDate(0, 0, 7)
+		case dto.RecurrenceMonthly:
+			startDate, endDate = addMonth(startDate), addMonth(endDate)
+		case dto.RecurrenceRamadan:
+			startDate, endDate = addHijriYear(startDate), addHijriYear(endDate)
+		default:
+			return dto.ReadingTarget{}, fmt.Errorf("unknown recurrence %q", readingTarget.Recurrence)
+		}
+	}
+
+	return dto.ReadingTarget{
+		Name:       readingTarget.Name,
+		UserID:     readingTarget.UserID,
+		StartDate:  startDate.Format("2006-01-02"),
+		EndDate:    endDate.Format("2006-01-02"),
+		StartPage:  readingTarget.StartPage,
+		EndPage:    readingTarget.EndPage,
+		Scope:      readingTarget.Scope,
+		Pages:      readingTarget.Pages,
+		IsPublic:   readingTarget.IsPublic,
+		Status:     dto.TargetStatusActive,
+		Recurrence: readingTarget.Recurrence,
+		CarryOver:  readingTarget.CarryOver,
+	}, nil
+}
+
+// carryOverTarget returns a target over the dates of next for the pages of
+// readingTarget still owed, from its first unread page to its end page. ok
+// is false when nothing is owed.
+func carryOverTarget(readingTarget, next dto.ReadingTarget) (dto.ReadingTarget, bool, error) {
+	progresses, err := getReadingProgressByUserIDTargetID(readingTarget.UserID, readingTarget.ID)
+	if err != nil {
+		return dto.ReadingTarget{}, false, err
+	}
+	coverage := newPageCoverage(progresses)
+
+	owed := math.Ceil(readingTarget.Pages - coverage.PagesRead(readingTarget.StartPage, readingTarget.EndPage))
+	startPage := readingTarget.StartPage
+	for startPage <= readingTarget.EndPage && coverage.Complete(startPage) {
+		startPage++
+	}
+	if owed < 1 || startPage > readingTarget.EndPage {
+		return dto.ReadingTarget{}, false, nil
+	}
+	if pages := float64(readingTarget.EndPage - startPage + 1); owed > pages {
+		owed = pages
+	}
+
+	return dto.ReadingTarget{
+		Name:      readingTarget.Name + " (sisa)",
+		UserID:    readingTarget.UserID,
+		StartDate: next.StartDate,
+		EndDate:   next.EndDate,
+		StartPage: startPage,
+		EndPage:   readingTarget.EndPage,
+		Pages:     owed,
+		IsPublic:  readingTarget.IsPublic,
+		Status:    dto.TargetStatusActive,
+	}, true, nil
+}
+
+// addMonth returns the same day a month later, the last day of the month
+// when that day does not exist or date is the last day of its month.
+func addMonth(date time.Time) time.Time {
+	firstDay := time.Date(date.Year(), date.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	lastDay := firstDay.AddDate(0, 1, -1).Day()
+	day := date.Day()
+	if day > lastDay || date.AddDate(0, 0, 1).Day() == 1 {
+		day = lastDay
+	}
+	return time.Date(firstDay.Year(), firstDay.Month(), day, 0, 0, 0, 0, time.UTC)
+}
+
+// addHijriYear returns the same Hijri day a year later, keeping the last
+// day of a month the last day.
+func addHijriYear(date time.Time) time.Time {
+	day := hijri.FromGregorian(date)
+	lastDay := day.Day == hijri.MonthLength(day.Year, day.Month)
+	day.Year++
+	if length := hijri.MonthLength(day.Year, day.Month); lastDay || day.Day > length {
+		day.Day = length
+	}
+	return hijri.ToGregorian(day)
+}
+
+func isValidRecurrence(recurrence string) bool {
+	switch recurrence {
+	case "", dto.RecurrenceWeekly, dto.RecurrenceMonthly, dto.RecurrenceRamadan:
+		return true
+	}
+	return false
+}
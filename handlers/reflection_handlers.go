@@ -0,0 +1,332 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/dto"
+	"github.com/daffashafwan/tadarus-yuk/internal/helpers"
+	"github.com/daffashafwan/tadarus-yuk/internal/quran"
+	"github.com/daffashafwan/tadarus-yuk/internal/storage"
+	"github.com/gorilla/mux"
+)
+
+const (
+	maxReflectionLength    = 5000
+	maxReflectionTags      = 10
+	maxReflectionTagLength = 50
+	publicReflectionsLimit = 50
+)
+
+// CreateReflection attaches a note to one of the user's progress entries.
+func CreateReflection(w http.ResponseWriter, r *http.Request) {
+	var reflection dto.Reflection
+	err := json.NewDecoder(r.Body).Decode(&reflection)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	vars := mux.Vars(r)
+	user, err := getUserByUsername(vars["id"])
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Error get user", nil)
+		return
+	}
+
+	readingProgress, err := store.ReadingProgress.GetByID(reflection.ProgressID)
+	if err != nil || readingProgress.UserID != user.ID {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Error get reading progress", nil)
+		return
+	}
+
+	if err := normalizeReflection(&reflection); err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid reflection", nil)
+		return
+	}
+	reflection.UserID = user.ID
+	reflection.Page = readingProgress.CurrentPage
+
+	err = store.Reflections.Create(&reflection)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error creating reflection", nil)
+		return
+	}
+
+	helpers.ResponseJSON(w, err, http.StatusCreated, "SUCCESS", reflection)
+}
+
+// GetReflections lists the user's notes, newest first. The q query
+// parameter searches the notes, tag and progressId filter them.
+func GetReflections(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	user, err := getUserByUsername(vars["id"])
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Error get user", nil)
+		return
+	}
+
+	filter, err := reflectionFilter(r)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid progressId", nil)
+		return
+	}
+
+	reflections, err := store.Reflections.Search(user.ID, filter)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching reflections", nil)
+		return
+	}
+
+	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", reflections)
+}
+
+func GetReflectionByID(w http.ResponseWriter, r *http.Request) {
+	reflection, status, err := getUserReflection(r)
+	if err != nil {
+		helpers.ResponseJSON(w, err, status, "Error fetching reflection", nil)
+		return
+	}
+
+	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", reflection)
+}
+
+// UpdateReflection replaces the note, tags, verse and visibility, the
+// progress entry stays the same.
+func UpdateReflection(w http.ResponseWriter, r *http.Request) {
+	reflection, status, err := getUserReflection(r)
+	if err != nil {
+		helpers.ResponseJSON(w, err, status, "Error fetching reflection", nil)
+		return
+	}
+
+	var reflectionUpdate dto.Reflection
+	err = json.NewDecoder(r.Body).Decode(&reflectionUpdate)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	if err := normalizeReflection(&reflectionUpdate); err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid reflection", nil)
+		return
+	}
+
+	reflection.Note = reflectionUpdate.Note
+	reflection.Tags = reflectionUpdate.Tags
+	reflection.VerseKey = reflectionUpdate.VerseKey
+	reflection.IsPublic = reflectionUpdate.IsPublic
+
+	err = store.Reflections.Update(&reflection)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error updating reflection", nil)
+		return
+	}
+
+	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", reflection)
+}
+
+func DeleteReflection(w http.ResponseWriter, r *http.Request) {
+	reflection, status, err := getUserReflection(r)
+	if err != nil {
+		helpers.ResponseJSON(w, err, status, "Error fetching reflection", nil)
+		return
+	}
+
+	err = store.Reflections.Delete(reflection.ID)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error deleting reflection", nil)
+		return
+	}
+
+	helpers.ResponseJSON(w, err, http.StatusNoContent, "SUCCESS", nil)
+}
+
+// ExportReflections downloads the user's notes as json (default), csv or
+// markdown, with the same filters as GetReflections.
+func ExportReflections(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	user, err := getUserByUsername(vars["id"])
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Error get user", nil)
+		return
+	}
+
+	filter, err := reflectionFilter(r)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid progressId", nil)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	contentTypes := map[string]string{
+		"json":     "application/json",
+		"csv":      "text/csv; charset=utf-8",
+		"markdown": "text/markdown; charset=utf-8",
+	}
+	if contentTypes[format] == "" {
+		helpers.ResponseJSON(w, nil, http.StatusBadRequest, "format must be json, csv or markdown", nil)
+		return
+	}
+
+	reflections, err := store.Reflections.Search(user.ID, filter)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching reflections", nil)
+		return
+	}
+
+	extension := format
+	if format == "markdown" {
+		extension = "md"
+	}
+	w.Header().Set("Content-Type", contentTypes[format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="reflections-%s.%s"`, time.Now().Format("2006-01-02"), extension))
+	w.WriteHeader(http.StatusOK)
+
+	switch format {
+	case "csv":
+		writer := csv.NewWriter(w)
+		writer.Write([]string{"id", "created_at", "page", "verse_key", "tags", "public", "note"})
+		for _, reflection := range reflections {
+			writer.Write([]string{
+				strconv.Itoa(reflection.ID),
+				reflection.CreatedAt.Format(time.RFC3339),
+				strconv.Itoa(reflection.Page),
+				reflection.VerseKey,
+				strings.Join(reflection.Tags, ";"),
+				strconv.FormatBool(reflection.IsPublic),
+				reflection.Note,
+			})
+		}
+		writer.Flush()
+	case "markdown":
+		var builder strings.Builder
+		builder.WriteString("# Reflections\n")
+		for _, reflection := range reflections {
+			builder.WriteString("\n## " + reflection.CreatedAt.Format("2006-01-02") + " - Page " + strconv.Itoa(reflection.Page))
+			if reflection.VerseKey != "" {
+				builder.WriteString(" (" + reflection.VerseKey + ")")
+			}
+			builder.WriteString("\n\n" + reflection.Note + "\n")
+			if len(reflection.Tags) > 0 {
+				builder.WriteString("\nTags: " + strings.Join(reflection.Tags, ", ") + "\n")
+			}
+		}
+		w.Write([]byte(builder.String()))
+	default:
+		json.NewEncoder(w).Encode(reflections)
+	}
+}
+
+// GetPublicReflections returns the newest public notes of every user, of
+// one verse with the verseKey query parameter.
+func GetPublicReflections(w http.ResponseWriter, r *http.Request) {
+	verseKey := r.URL.Query().Get("verseKey")
+	if verseKey != "" {
+		if _, err := quran.GetVerseByKey(verseKey); err != nil {
+			helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid verseKey", nil)
+			return
+		}
+	}
+
+	reflections, err := store.Reflections.GetPublic(verseKey, publicReflectionsLimit)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching reflections", nil)
+		return
+	}
+
+	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", reflections)
+}
+
+// getUserReflection loads the reflection in the rid path variable and checks
+// it belongs to the user in id, returning the status to reply with.
+func getUserReflection(r *http.Request) (dto.Reflection, int, error) {
+	vars := mux.Vars(r)
+	user, err := getUserByUsername(vars["id"])
+	if err != nil {
+		return dto.Reflection{}, http.StatusBadRequest, err
+	}
+
+	id, err := strconv.Atoi(vars["rid"])
+	if err != nil {
+		return dto.Reflection{}, http.StatusBadRequest, fmt.Errorf("Reflection with ID %s not found", vars["rid"])
+	}
+
+	reflection, err := store.Reflections.GetByID(id)
+	if errors.Is(err, storage.ErrNotFound) || (err == nil && reflection.UserID != user.ID) {
+		return dto.Reflection{}, http.StatusNotFound, fmt.Errorf("Reflection with ID %s not found", vars["rid"])
+	} else if err != nil {
+		return dto.Reflection{}, http.StatusInternalServerError, err
+	}
+	return reflection, http.StatusOK, nil
+}
+
+func reflectionFilter(r *http.Request) (dto.ReflectionFilter, error) {
+	queryParams := r.URL.Query()
+	filter := dto.ReflectionFilter{
+		Query: strings.TrimSpace(queryParams.Get("q")),
+		Tag:   strings.ToLower(strings.TrimSpace(queryParams.Get("tag"))),
+	}
+	if progressID := queryParams.Get("progressId"); progressID != "" {
+		id, err := strconv.Atoi(progressID)
+		if err != nil {
+			return dto.ReflectionFilter{}, err
+		}
+		filter.ProgressID = id
+	}
+	return filter, nil
+}
+
+// normalizeReflection trims the note, lowercases and dedupes the tags and
+// checks the verse exists.
+func normalizeReflection(reflection *dto.Reflection) error {
+	reflection.Note = strings.TrimSpace(reflection.Note)
+	if reflection.Note == "" {
+		return errors.New("note is required")
+	}
+	if len([]rune(reflection.Note)) > maxReflectionLength {
+		return fmt.Errorf("note can not be longer than %d characters", maxReflectionLength)
+	}
+
+	tags := make([]string, 0, len(reflection.Tags))
+	for _, tag := range reflection.Tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || containsTag(tags, tag) {
+			continue
+		}
+		if len([]rune(tag)) > maxReflectionTagLength {
+			return fmt.Errorf("a tag can not be longer than %d characters", maxReflectionTagLength)
+		}
+		tags = append(tags, tag)
+	}
+	if len(tags) > maxReflectionTags {
+		return fmt.Errorf("a reflection can have at most %d tags", maxReflectionTags)
+	}
+	reflection.Tags = tags
+
+	if reflection.VerseKey != "" {
+		verse, err := quran.GetVerseByKey(reflection.VerseKey)
+		if err != nil {
+			return err
+		}
+		reflection.VerseKey = verse.Key
+	}
+	return nil
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
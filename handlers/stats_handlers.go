@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/dto"
+	"github.com/daffashafwan/tadarus-yuk/internal/helpers"
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultStatsDays = 30
+	maxStatsDays     = 366
+	statsWeeks       = 12
+	statsMonths      = 12
+	heatmapDays      = 365
+)
+
+// GetReadingStats returns the reading statistics of a user. The days query
+// parameter sets the daily window and tz overrides the user timezone.
+func GetReadingStats(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	user, err := getUserByUsername(userID)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Error get user", nil)
+		return
+	}
+
+	queryParams := r.URL.Query()
+	timezone := user.Timezone
+	if queryParams.Get("tz") != "" {
+		timezone = queryParams.Get("tz")
+	}
+	location, err := userLocation(timezone)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid timezone", nil)
+		return
+	}
+
+	days := defaultStatsDays
+	if queryParams.Get("days") != "" {
+		days, err = strconv.Atoi(queryParams.Get("days"))
+		if err != nil || days < 1 || days > maxStatsDays {
+			helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid number of days", nil)
+			return
+		}
+	}
+
+	today := localDay(time.Now(), location)
+	stats := dto.ReadingStats{
+		Timezone: location.String(),
+		Today:    today.Format("2006-01-02"),
+	}
+
+	periods := []struct {
+		period string
+		from   time.Time
+		result *[]dto.PeriodPages
+	}{
+		{dto.StatsPeriodDay, today.AddDate(0, 0, 1-days), &stats.Daily},
+		{dto.StatsPeriodWeek, today.AddDate(0, 0, -7*(statsWeeks-1)), &stats.Weekly},
+		{dto.StatsPeriodMonth, today.AddDate(0, 1-statsMonths, 0), &stats.Monthly},
+		{dto.StatsPeriodDay, today.AddDate(0, 0, 1-heatmapDays), &stats.Heatmap},
+	}
+	for _, p := range periods {
+		*p.result, err = store.ReadingStats.GetPagesPerPeriod(user.ID, p.period, location.String(), p.from, today)
+		if err != nil {
+			helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching reading statistics", nil)
+			return
+		}
+	}
+
+	summary, err := store.ReadingStats.GetSummary(user.ID, location.String())
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching reading statistics", nil)
+		return
+	}
+	stats.TotalPages = summary.TotalPages
+	stats.ReviewPages = summary.ReviewPages
+	stats.ActiveDays = summary.ActiveDays
+	if summary.ActiveDays > 0 {
+		firstDay, _ := time.Parse("2006-01-02", summary.FirstDay)
+		stats.AveragePagesPerDay = roundOneDecimal(summary.TotalPages / float64(daysBetween(firstDay, today)+1))
+		stats.AveragePagesPerActiveDay = roundOneDecimal(summary.TotalPages / float64(summary.ActiveDays))
+		stats.BestDay = &dto.PeriodPages{Date: summary.BestDay, Pages: summary.BestDayPages, ReviewPages: summary.BestDayReviewPages}
+	}
+
+	stats.Targets, err = getTargetProjections(user.ID, today)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching reading statistics", nil)
+		return
+	}
+
+	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", stats)
+}
+
+// getTargetProjections projects the finish date of every active target from
+// the pages per day kept since the target started, paused days left out.
+func getTargetProjections(userID int, today time.Time) ([]dto.TargetProjection, error) {
+	readingTargets, err := store.ReadingTargets.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	targetPages, err := store.ReadingStats.GetTargetPages(userID)
+	if err != nil {
+		return nil, err
+	}
+	pagesRead := make(map[int]float64)
+	for _, pages := range targetPages {
+		pagesRead[pages.TargetID] = pages.PagesRead
+	}
+
+	projections := make([]dto.TargetProjection, 0)
+	for _, readingTarget := range readingTargets {
+		if targetStatus(readingTarget, today) != dto.TargetStatusActive {
+			continue
+		}
+		projection := dto.TargetProjection{
+			TargetID:   readingTarget.ID,
+			Name:       readingTarget.Name,
+			EndDate:    dateOnly(readingTarget.EndDate),
+			TotalPages: readingTarget.EndPage - readingTarget.StartPage + 1,
+			PagesRead:  pagesRead[readingTarget.ID],
+		}
+		if projection.PagesRead >= float64(projection.TotalPages) {
+			continue
+		}
+
+		startDate, err := time.Parse("2006-01-02", dateOnly(readingTarget.StartDate))
+		if err != nil {
+			return nil, err
+		}
+		elapsed := daysBetween(startDate, today) + 1 - readingTarget.PausedDays
+		if elapsed < 1 {
+			elapsed = 1
+		}
+		projection.PagesPerDay = roundOneDecimal(projection.PagesRead / float64(elapsed))
+
+		if projection.PagesRead > 0 {
+			pace := projection.PagesRead / float64(elapsed)
+			remainingDays := int(math.Ceil((float64(projection.TotalPages) - projection.PagesRead) / pace))
+			projection.ProjectedDate = today.AddDate(0, 0, remainingDays).Format("2006-01-02")
+			projection.OnSchedule = projection.ProjectedDate <= projection.EndDate
+		}
+		projections = append(projections, projection)
+	}
+	return projections, nil
+}
+
+func roundOneDecimal(value float64) float64 {
+	return math.Round(value*10) / 10
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/dto"
+	"github.com/daffashafwan/tadarus-yuk/internal/helpers"
+	"github.com/gorilla/mux"
+)
+
+const maxStreakGraceDays = 7
+
+// GetReadingStreaks returns the current, longest and past reading streaks of
+// a user. The tz and graceDays query parameters override the user settings.
+func GetReadingStreaks(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	user, err := getUserByUsername(userID)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Error get user", nil)
+		return
+	}
+
+	queryParams := r.URL.Query()
+	timezone := user.Timezone
+	if queryParams.Get("tz") != "" {
+		timezone = queryParams.Get("tz")
+	}
+	location, err := userLocation(timezone)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid timezone", nil)
+		return
+	}
+
+	graceDays := user.StreakGraceDays
+	if queryParams.Get("graceDays") != "" {
+		graceDays, err = strconv.Atoi(queryParams.Get("graceDays"))
+		if err != nil || graceDays < 0 || graceDays > maxStreakGraceDays {
+			helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid number of grace days", nil)
+			return
+		}
+	}
+
+	readingProgresses, err := store.ReadingProgress.GetByUserID(user.ID)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching get all reading progress", nil)
+		return
+	}
+
+	streaks := buildReadingStreaks(readingProgresses, time.Now(), location, graceDays)
+	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", streaks)
+}
+
+// userLocation loads an IANA timezone, an empty name falls back to
+// DEFAULT_TIMEZONE and then to UTC.
+func userLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		timezone = os.Getenv("DEFAULT_TIMEZONE")
+	}
+	return time.LoadLocation(timezone)
+}
+
+// localDay returns the calendar day of t in location as a UTC midnight, so
+// days can be subtracted without daylight saving gaps.
+func localDay(t time.Time, location *time.Location) time.Time {
+	year, month, day := t.In(location).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// buildReadingStreaks groups the reading days into streaks. A streak goes on
+// as long as no more than graceDays days in a row are missed, and today does
+// not count as missed until it is over.
+func buildReadingStreaks(readingProgresses []dto.ReadingProgress, now time.Time, location *time.Location, graceDays int) dto.ReadingStreaks {
+	today := localDay(now, location)
+	streaks := dto.ReadingStreaks{
+		Timezone:  location.String(),
+		GraceDays: graceDays,
+		Today:     today.Format("2006-01-02"),
+		History:   make([]dto.ReadingStreak, 0),
+	}
+
+	readDays := make(map[time.Time]bool)
+	for _, readingProgress := range readingProgresses {
+		readDays[localDay(readingProgress.ReadAt, location)] = true
+	}
+	days := make([]time.Time, 0, len(readDays))
+	for day := range readDays {
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	streaks.ReadToday = readDays[today]
+
+	var history []dto.ReadingStreak
+	var last time.Time
+	for _, day := range days {
+		missed := daysBetween(last, day) - 1
+		if len(history) == 0 || missed > graceDays {
+			history = append(history, dto.ReadingStreak{StartDate: day.Format("2006-01-02")})
+		} else {
+			history[len(history)-1].GraceDaysUsed += missed
+		}
+		streak := &history[len(history)-1]
+		streak.EndDate = day.Format("2006-01-02")
+		streak.Days++
+		last = day
+	}
+
+	for i := range history {
+		if history[i].Days > streaks.LongestStreak {
+			streaks.LongestStreak = history[i].Days
+			streaks.Longest = &history[i]
+		}
+	}
+	if len(history) > 0 && daysBetween(last, today)-1 <= graceDays {
+		streaks.Current = &history[len(history)-1]
+		streaks.CurrentStreak = streaks.Current.Days
+	}
+
+	// Newest streak first
+	for i := len(history) - 1; i >= 0; i-- {
+		streaks.History = append(streaks.History, history[i])
+	}
+	return streaks
+}
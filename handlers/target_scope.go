@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/dto"
+	"github.com/daffashafwan/tadarus-yuk/internal/quran"
+)
+
+// resolveTargetScope sets the page range of a target from its scope, and
+// Pages to every page of the range when it is not given. A verse range
+// covers the pages its verses are on.
+func resolveTargetScope(readingTarget *dto.ReadingTarget) error {
+	scope := readingTarget.Scope
+	if scope == nil {
+		return nil
+	}
+	scope.Verses = strings.TrimSpace(scope.Verses)
+
+	set := 0
+	for _, isSet := range []bool{len(scope.Juz) > 0, len(scope.Surah) > 0, len(scope.Hizb) > 0, scope.Verses != ""} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		return errors.New("scope must have one of juz, surah, hizb or verses")
+	}
+
+	var startPage, endPage int
+	var err error
+	switch {
+	case len(scope.Juz) > 0:
+		startPage, endPage, err = scopePages(scope.Juz, quran.GetJuzPages)
+	case len(scope.Hizb) > 0:
+		startPage, endPage, err = scopePages(scope.Hizb, quran.GetHizbPages)
+	case len(scope.Surah) > 0:
+		startPage, endPage, err = scopePages(scope.Surah, func(number int) (int, int, error) {
+			surah, err := quran.GetSurah(number)
+			return surah.FirstPage, surah.LastPage, err
+		})
+	default:
+		startPage, endPage, err = versePages(scope.Verses)
+	}
+	if err != nil {
+		return err
+	}
+
+	readingTarget.StartPage = startPage
+	readingTarget.EndPage = endPage
+	if readingTarget.Pages == 0 {
+		readingTarget.Pages = float64(endPage - startPage + 1)
+	}
+	return nil
+}
+
+// scopePages returns the first page of the first division of scopeRange
+// and the last page of its last one, pages gives the pages of one division.
+func scopePages(scopeRange dto.ScopeRange, pages func(int) (int, int, error)) (int, int, error) {
+	if len(scopeRange) > 2 {
+		return 0, 0, errors.New("scope range must be a number or a [first, last] pair")
+	}
+	first, last := scopeRange.Bounds()
+	if first > last {
+		return 0, 0, errors.New("scope range must not end before it starts")
+	}
+	startPage, _, err := pages(first)
+	if err != nil {
+		return 0, 0, err
+	}
+	_, endPage, err := pages(last)
+	if err != nil {
+		return 0, 0, err
+	}
+	return startPage, endPage, nil
+}
+
+// versePages returns the pages of a "2:1-2:141" verse range.
+func versePages(verseRange string) (int, int, error) {
+	keys := strings.Split(verseRange, "-")
+	if len(keys) != 2 {
+		return 0, 0, errors.New(`verses must be a range like "2:1-2:141"`)
+	}
+	verses, err := quran.GetVerseRange(strings.TrimSpace(keys[0]), strings.TrimSpace(keys[1]))
+	if err != nil {
+		return 0, 0, err
+	}
+	return verses[0].Page, verses[len(verses)-1].Page, nil
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/dto"
+	"github.com/daffashafwan/tadarus-yuk/internal/quran"
+)
+
+func TestCreateReadingTargetWithScope(t *testing.T) {
+	newTestStore(t)
+	user := newTestUser(t, "fulan", "")
+	vars := map[string]string{"id": user.Username}
+
+	var readingTarget dto.ReadingTarget
+	body := dto.ReadingTarget{Name: "Juz Amma", StartDate: "2026-10-01", EndDate: "2026-10-30", Scope: &dto.TargetScope{Juz: dto.ScopeRange{30}}}
+	response := serve(t, CreateReadingTargetByUserID, http.MethodPost, vars, body, &readingTarget)
+	if response.Code != http.StatusCreated {
+		t.Fatalf("create target: status = %d (%v)", response.Code, response.Message)
+	}
+
+	startPage, endPage, err := quran.GetJuzPages(30)
+	if err != nil {
+		t.Fatalf("juz pages: %v", err)
+	}
+	if readingTarget.StartPage != startPage || readingTarget.EndPage != endPage || readingTarget.Pages != float64(endPage-startPage+1) {
+		t.Errorf("target = pages %d - %d (%v), want %d - %d", readingTarget.StartPage, readingTarget.EndPage, readingTarget.Pages, startPage, endPage)
+	}
+	if readingTarget.Label != "Juz 30" {
+		t.Errorf("label = %q, want %q", readingTarget.Label, "Juz 30")
+	}
+
+	stored, err := store.ReadingTargets.GetByID(readingTarget.ID)
+	if err != nil {
+		t.Fatalf("get target: %v", err)
+	}
+	if stored.Scope == nil || len(stored.Scope.Juz) != 1 || stored.Scope.Juz[0] != 30 {
+		t.Errorf("stored scope = %+v, want juz 30", stored.Scope)
+	}
+
+	body.Scope = &dto.TargetScope{Juz: dto.ScopeRange{30}, Surah: dto.ScopeRange{1}}
+	if response := serve(t, CreateReadingTargetByUserID, http.MethodPost, vars, body, nil); response.Code != http.StatusBadRequest {
+		t.Errorf("two scopes: status = %d, want %d", response.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/dto"
+	"github.com/daffashafwan/tadarus-yuk/internal/helpers"
+	"github.com/daffashafwan/tadarus-yuk/internal/quran"
+	"github.com/daffashafwan/tadarus-yuk/internal/storage"
+	"github.com/gorilla/mux"
+)
+
+// GetTargetTemplates returns the template catalogue.
+func GetTargetTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := store.Templates.GetAll()
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching target templates", nil)
+		return
+	}
+
+	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", templates)
+}
+
+// CreateTargetTemplate adds a template to the catalogue. Pages defaults to
+// every page of the range.
+func CreateTargetTemplate(w http.ResponseWriter, r *http.Request) {
+	var template dto.TargetTemplate
+	err := json.NewDecoder(r.Body).Decode(&template)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	if err := normalizeTargetTemplate(&template); err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid target template", nil)
+		return
+	}
+
+	err = store.Templates.Create(&template)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error creating target template", nil)
+		return
+	}
+
+	helpers.ResponseJSON(w, err, http.StatusCreated, "SUCCESS", template)
+}
+
+// DeleteTargetTemplate removes a template, targets made from it are kept.
+func DeleteTargetTemplate(w http.ResponseWriter, r *http.Request) {
+	template, status, err := getTargetTemplate(mux.Vars(r)["tid"])
+	if err != nil {
+		helpers.ResponseJSON(w, err, status, "Error get target template", nil)
+		return
+	}
+
+	err = store.Templates.Delete(template.ID)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error deleting target template", nil)
+		return
+	}
+
+	helpers.ResponseJSON(w, err, http.StatusNoContent, "SUCCESS", nil)
+}
+
+// CreateReadingTargetFromTemplate makes a reading target for the user from a
+// template, starting on the requested date.
+func CreateReadingTargetFromTemplate(w http.ResponseWriter, r *http.Request) {
+	var request dto.TargetFromTemplateRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	vars := mux.Vars(r)
+	user, err := getUserByUsername(vars["id"])
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Error user not found", nil)
+		return
+	}
+
+	template, status, err := getTargetTemplate(vars["tid"])
+	if err != nil {
+		helpers.ResponseJSON(w, err, status, "Error get target template", nil)
+		return
+	}
+
+	if request.StartDate == "" && request.HijriStartDate != "" {
+		request.StartDate, err = gregorianDate(request.HijriStartDate)
+		if err != nil {
+			helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid hijri date", nil)
+			return
+		}
+	}
+
+	startDate, err := time.Parse("2006-01-02", request.StartDate)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid start date", nil)
+		return
+	}
+
+	readingTarget := templateTarget(template, startDate)
+	readingTarget.UserID = user.ID
+	readingTarget.IsPublic = request.IsPublic
+	readingTarget.CarryOver = request.CarryOver
+	if name := strings.TrimSpace(request.Name); name != "" {
+		readingTarget.Name = name
+	}
+
+	// The calendar event is pushed by the calendar sync worker
+	err = store.ReadingTargets.CreateWithCalendarSync(&readingTarget)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error creating reading target", nil)
+		return
+	}
+
+	helpers.ResponseJSON(w, err, http.StatusCreated, "SUCCESS", readingTarget)
+}
+
+// templateTarget returns the target a template makes from startDate,
+// moving the start forward to the template weekday.
+func templateTarget(template dto.TargetTemplate, startDate time.Time) dto.ReadingTarget {
+	if template.Weekday != nil {
+		startDate = startDate.AddDate(0, 0, (*template.Weekday-int(startDate.Weekday())+7)%7)
+	}
+	return dto.ReadingTarget{
+		Name:       template.Name,
+		StartDate:  startDate.Format("2006-01-02"),
+		EndDate:    startDate.AddDate(0, 0, template.DurationDays-1).Format("2006-01-02"),
+		StartPage:  template.StartPage,
+		EndPage:    template.EndPage,
+		Pages:      template.Pages,
+		Status:     dto.TargetStatusActive,
+		Recurrence: template.Recurrence,
+	}
+}
+
+func getTargetTemplate(templateID string) (dto.TargetTemplate, int, error) {
+	id, err := strconv.Atoi(templateID)
+	if err != nil {
+		return dto.TargetTemplate{}, http.StatusBadRequest, fmt.Errorf("Target template with ID %s not found", templateID)
+	}
+
+	template, err := store.Templates.GetByID(id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return dto.TargetTemplate{}, http.StatusNotFound, fmt.Errorf("Target template with ID %s not found", templateID)
+	} else if err != nil {
+		return dto.TargetTemplate{}, http.StatusInternalServerError, err
+	}
+	return template, http.StatusOK, nil
+}
+
+func normalizeTargetTemplate(template *dto.TargetTemplate) error {
+	template.Name = strings.TrimSpace(template.Name)
+	template.Description = strings.TrimSpace(template.Description)
+	if template.Name == "" {
+		return errors.New("name is required")
+	}
+	if template.StartPage < 1 || template.EndPage > quran.TotalPages || template.StartPage > template.EndPage {
+		return fmt.Errorf("page range must be within 1 and %d", quran.TotalPages)
+	}
+	rangePages := float64(template.EndPage - template.StartPage + 1)
+	if template.Pages == 0 {
+		template.Pages = rangePages
+	}
+	if template.Pages != math.Trunc(template.Pages) {
+		return errors.New("pages must be a whole number")
+	}
+	if template.Pages < 1 || template.Pages > rangePages {
+		return errors.New("pages must be within the page range")
+	}
+	if template.DurationDays < 1 {
+		return errors.New("durationDays must be at least 1")
+	}
+	if template.Weekday != nil && (*template.Weekday < 0 || *template.Weekday > 6) {
+		return errors.New("weekday must be within 0 and 6")
+	}
+	if !isValidRecurrence(template.Recurrence) {
+		return errors.New("recurrence must be weekly, monthly or ramadan")
+	}
+	return nil
+}
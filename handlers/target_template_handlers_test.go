@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/dto"
+)
+
+func TestNormalizeTargetTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template dto.TargetTemplate
+		pages    float64
+		wantErr  bool
+	}{
+		{"pages default to the range", dto.TargetTemplate{Name: "Juz 1", StartPage: 1, EndPage: 21, DurationDays: 7}, 21, false},
+		{"fractional pages", dto.TargetTemplate{Name: "Juz 1", StartPage: 1, EndPage: 21, Pages: 10.5, DurationDays: 7}, 0, true},
+		{"pages over the range", dto.TargetTemplate{Name: "Juz 1", StartPage: 1, EndPage: 21, Pages: 22, DurationDays: 7}, 0, true},
+		{"no name", dto.TargetTemplate{StartPage: 1, EndPage: 21, DurationDays: 7}, 0, true},
+		{"unknown recurrence", dto.TargetTemplate{Name: "Juz 1", StartPage: 1, EndPage: 21, DurationDays: 7, Recurrence: "daily"}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := tt.template
+			err := normalizeTargetTemplate(&template)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && template.Pages != tt.pages {
+				t.Errorf("pages = %v, want %v", template.Pages, tt.pages)
+			}
+		})
+	}
+}
+
+func TestCreateReadingTargetFromTemplate(t *testing.T) {
+	newTestStore(t)
+	user := newTestUser(t, "fulan", "")
+	friday := 5
+	template := dto.TargetTemplate{Name: "Al-Kahf", StartPage: 293, EndPage: 304, DurationDays: 1, Weekday: &friday, Recurrence: dto.RecurrenceWeekly}
+	response := serve(t, CreateTargetTemplate, http.MethodPost, nil, template, &template)
+	if response.Code != http.StatusCreated {
+		t.Fatalf("create template: status = %d (%v)", response.Code, response.Message)
+	}
+
+	var readingTarget dto.ReadingTarget
+	vars := map[string]string{"id": user.Username, "tid": strconv.Itoa(template.ID)}
+	response = serve(t, CreateReadingTargetFromTemplate, http.MethodPost, vars, dto.TargetFromTemplateRequest{StartDate: "2026-10-18"}, &readingTarget)
+	if response.Code != http.StatusCreated {
+		t.Fatalf("create target: status = %d (%v)", response.Code, response.Message)
+	}
+	// 2026-10-18 is a Sunday, the target moves to the next Friday
+	if readingTarget.StartDate != "2026-10-23" || readingTarget.EndDate != "2026-10-23" {
+		t.Errorf("dates = %s - %s, want 2026-10-23", readingTarget.StartDate, readingTarget.EndDate)
+	}
+	if readingTarget.Pages != 12 || readingTarget.Recurrence != dto.RecurrenceWeekly || readingTarget.UserID != user.ID {
+		t.Errorf("target = %+v, want 12 weekly pages for the user", readingTarget)
+	}
+}
@@ -1,41 +1,29 @@
 package handlers
 
 import (
-	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
-	"github.com/daffashafwan/tadarus-yuk/db"
 	"github.com/daffashafwan/tadarus-yuk/internal/authorization"
 	"github.com/daffashafwan/tadarus-yuk/internal/dto"
 	"github.com/daffashafwan/tadarus-yuk/internal/helpers"
+	"github.com/daffashafwan/tadarus-yuk/internal/storage"
 	"github.com/gorilla/mux"
 )
 
 // GetAllUsersHandler handles requests to get all users.
 func GetAllUsers(w http.ResponseWriter, r *http.Request) {
 	// Query all users from the database
-	query := "SELECT * FROM users"
-	rows, err := db.GetDB().Query(query)
+	users, err := store.Users.GetAll()
 	if err != nil {
 		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error fetching get all users", nil)
 		return
 	}
-	defer rows.Close()
-
-	var users []dto.User
-	for rows.Next() {
-		var user dto.User
-		err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.Password)
-		if err != nil {
-			helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error scanning user row", nil)
-			return
-		}
-		users = append(users, user)
-	}
 
 	helpers.ResponseJSON(w, err, http.StatusOK, "SUCCESS", users)
 }
@@ -82,7 +70,7 @@ func Register(w http.ResponseWriter, r *http.Request) {
 		helpers.ResponseJSON(w, err, http.StatusBadRequest, "username has taken", nil)
 		return
 	}
-	err = createUser(user, hashedPassword)
+	err = createUser(&user, hashedPassword)
 	if err != nil {
 		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error creating user", nil)
 		return
@@ -91,10 +79,8 @@ func Register(w http.ResponseWriter, r *http.Request) {
 	helpers.ResponseJSON(w, err, http.StatusCreated, "SUCCESS", user)
 }
 
-func createUser(user dto.User, hashedPassword string) error {
-	query := "INSERT INTO users (username, email, password, google_token, display_name) VALUES ($1, $2, $3, $4, $5) RETURNING id"
-	err := db.GetDB().QueryRow(query, user.Username, user.Email, hashedPassword, user.GoogleToken, user.Email).Scan(&user.ID)
-	return err
+func createUser(user *dto.User, hashedPassword string) error {
+	return store.Users.Create(user, hashedPassword)
 }
 
 // UpdateUserHandler handles requests to update a user by ID.
@@ -109,8 +95,13 @@ func UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Decode the updated user data from the request body
-	var updatedUser dto.User
+	// Decode the updated user data from the request body, the settings are
+	// only changed when they are sent
+	var updatedUser struct {
+		dto.User
+		Timezone        *string `json:"timezone"`
+		StreakGraceDays *int    `json:"streakGraceDays"`
+	}
 	err = json.NewDecoder(r.Body).Decode(&updatedUser)
 	if err != nil {
 		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Invalid request body", nil)
@@ -120,6 +111,20 @@ func UpdateUser(w http.ResponseWriter, r *http.Request) {
 	// Update user data based on the request body
 	// For example, update user fields like username, email, etc.
 	user.DisplayName = updatedUser.DisplayName
+	if updatedUser.Timezone != nil {
+		if _, err := time.LoadLocation(*updatedUser.Timezone); err != nil {
+			helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid timezone", nil)
+			return
+		}
+		user.Timezone = *updatedUser.Timezone
+	}
+	if updatedUser.StreakGraceDays != nil {
+		if *updatedUser.StreakGraceDays < 0 || *updatedUser.StreakGraceDays > maxStreakGraceDays {
+			helpers.ResponseJSON(w, err, http.StatusBadRequest, "invalid number of grace days", nil)
+			return
+		}
+		user.StreakGraceDays = *updatedUser.StreakGraceDays
+	}
 	// Update other fields as needed
 
 	// Save the updated user data to the database
@@ -133,9 +138,7 @@ func UpdateUser(w http.ResponseWriter, r *http.Request) {
 }
 
 func updateUser(user dto.User) (error) {
-	query := "UPDATE users SET username = $1, email = $2, google_token = $3, display_name = $4 WHERE id = $5"
-	_, err := db.GetDB().Exec(query, user.Username, user.Email, user.GoogleToken, user.DisplayName, user.ID)
-	return err
+	return store.Users.Update(user)
 }
 
 // DeleteUserHandler handles requests to delete a user by ID.
@@ -143,9 +146,14 @@ func DeleteUser(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	userID := vars["id"]
 
+	id, err := strconv.Atoi(userID)
+	if err != nil {
+		helpers.ResponseJSON(w, err, http.StatusBadRequest, "Invalid user ID", nil)
+		return
+	}
+
 	// Delete the user from the database by ID
-	query := "DELETE FROM users WHERE id = $1"
-	_, err := db.GetDB().Exec(query, userID)
+	err = store.Users.Delete(id)
 	if err != nil {
 		helpers.ResponseJSON(w, err, http.StatusInternalServerError, "Error deleting user", nil)
 		return
@@ -218,12 +226,8 @@ func getUserByID(userID string) (dto.User, error) {
 		return dto.User{}, err
 	}
 
-	query := "SELECT * FROM users WHERE id = $1"
-	row := db.GetDB().QueryRow(query, userIDDecrypt)
-
-	var user dto.User
-	err = row.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.GoogleToken, &user.DisplayName)
-	if err == sql.ErrNoRows {
+	user, err := store.Users.GetByID(userIDDecrypt)
+	if err == storage.ErrNotFound {
 		return dto.User{}, fmt.Errorf("user with ID %s not found", userID)
 	} else if err != nil {
 		log.Printf("Error : %v", err.Error())
@@ -268,12 +272,8 @@ func authenticateAdmin(username, password string) (bool, int, error) {
 // getUserByID retrieves user data from the database by username.
 func getUserByUsername(username string) (dto.User, error) {
 	// Query user data from the database by username
-	query := "SELECT * FROM users WHERE username = $1"
-	row := db.GetDB().QueryRow(query, username)
-
-	var user dto.User
-	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.GoogleToken, &user.DisplayName)
-	if err == sql.ErrNoRows {
+	user, err := store.Users.GetByUsername(username)
+	if err == storage.ErrNotFound {
 		return dto.User{}, fmt.Errorf("username not found")
 	} else if err != nil {
 		log.Printf("Error : %v", err.Error())
@@ -285,12 +285,8 @@ func getUserByUsername(username string) (dto.User, error) {
 
 func getUserByEmail(email string) (dto.User, error) {
 	// Query user data from the database by username
-	query := "SELECT * FROM users WHERE email = $1"
-	row := db.GetDB().QueryRow(query, email)
-
-	var user dto.User
-	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.GoogleToken, &user.DisplayName)
-	if err == sql.ErrNoRows {
+	user, err := store.Users.GetByEmail(email)
+	if err == storage.ErrNotFound {
 		return dto.User{}, fmt.Errorf("username not found")
 	} else if err != nil {
 		log.Printf("Error : %v", err.Error())
@@ -302,12 +298,8 @@ func getUserByEmail(email string) (dto.User, error) {
 
 func getAdminByUsername(username string) (dto.Admin, error) {
 	// Query user data from the database by username
-	query := "SELECT * FROM admin WHERE username = $1"
-	row := db.GetDB().QueryRow(query, username)
-
-	var admin dto.Admin
-	err := row.Scan(&admin.ID, &admin.Username, &admin.Email, &admin.Password)
-	if err == sql.ErrNoRows {
+	admin, err := store.Users.GetAdminByUsername(username)
+	if err == storage.ErrNotFound {
 		return dto.Admin{}, fmt.Errorf("username not found")
 	} else if err != nil {
 		log.Printf("Error : %v", err.Error())
@@ -320,13 +312,9 @@ func getAdminByUsername(username string) (dto.Admin, error) {
 func getUserByIDWithoutEncrypt(userID int) (dto.User, error) {
 	// Query user data from the database by ID
 
-	query := "SELECT * FROM users WHERE id = $1"
-	row := db.GetDB().QueryRow(query, userID)
-
-	var user dto.User
-	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.GoogleToken, &user.DisplayName)
-	if err == sql.ErrNoRows {
-		return dto.User{}, fmt.Errorf("user with ID %s not found", userID)
+	user, err := store.Users.GetByID(userID)
+	if err == storage.ErrNotFound {
+		return dto.User{}, fmt.Errorf("user with ID %d not found", userID)
 	} else if err != nil {
 		log.Printf("Error : %v", err.Error())
 		return dto.User{}, err
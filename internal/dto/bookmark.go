@@ -0,0 +1,33 @@
+package dto
+
+import "time"
+
+const (
+	BookmarkSourceAuto   = "auto"
+	BookmarkSourceManual = "manual"
+)
+
+// BookmarkPin is a bookmark set by the user. TargetID is 0 when it is not
+// tied to a target and VerseKey is empty for the start of the page.
+type BookmarkPin struct {
+	UserID    int       `json:"userId"`
+	TargetID  int       `json:"targetId"`
+	Page      int       `json:"page"`
+	VerseKey  string    `json:"verseKey"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Bookmark is where to continue reading, either the last position read or
+// the pinned one.
+type Bookmark struct {
+	Source         string    `json:"source"`
+	TargetID       int       `json:"targetId,omitempty"`
+	TargetName     string    `json:"targetName,omitempty"`
+	Page           int       `json:"page"`
+	VerseKey       string    `json:"verseKey"`
+	SurahNumber    int       `json:"surahNumber"`
+	SurahName      string    `json:"surahName"`
+	Juz            int       `json:"juz"`
+	NextUnreadPage int       `json:"nextUnreadPage,omitempty"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
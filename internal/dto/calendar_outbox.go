@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+const (
+	CalendarSyncPending  = "PENDING"
+	CalendarSyncRetrying = "RETRYING"
+	CalendarSyncSynced   = "SYNCED"
+	CalendarSyncSkipped  = "SKIPPED"
+	CalendarSyncFailed   = "FAILED"
+	CalendarOutboxDone   = "DONE"
+)
+
+// CalendarOutbox is a Google Calendar change waiting to be pushed.
+// Operation is one of ADD, EDIT or DELETE, like externalDto.CalendarEvent.Type.
+type CalendarOutbox struct {
+	ID               int
+	TargetID         int
+	UserID           int
+	Operation        string
+	GoogleCalendarID string
+	Status           string
+	Attempts         int
+	NextAttemptAt    time.Time
+	LastError        string
+	CreatedAt        time.Time
+}
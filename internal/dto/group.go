@@ -0,0 +1,102 @@
+package dto
+
+import "time"
+
+const (
+	GroupAssignModeJuz    = "juz"
+	GroupAssignModePages  = "pages"
+	GroupAssignModeManual = "manual"
+
+	GroupAssignmentActive     = "active"
+	GroupAssignmentReassigned = "reassigned"
+)
+
+// Group is a study circle. The owner is always one of the members.
+type Group struct {
+	ID        int           `json:"id"`
+	Name      string        `json:"name"`
+	OwnerID   int           `json:"ownerId"`
+	CreatedAt time.Time     `json:"createdAt"`
+	Members   []GroupMember `json:"members"`
+}
+
+type GroupMember struct {
+	UserID      int       `json:"userId"`
+	Username    string    `json:"username"`
+	DisplayName string    `json:"displayName"`
+	JoinedAt    time.Time `json:"joinedAt"`
+}
+
+// GroupTarget is a khatam shared by a group. Each assignment gives a page
+// range to a member as a personal reading target, so members log progress
+// the usual way. Progress, PagesRead, UnassignedPages and CompletedAt are
+// computed from the assignments.
+type GroupTarget struct {
+	ID              int               `json:"id"`
+	GroupID         int               `json:"groupId"`
+	Name            string            `json:"name"`
+	StartDate       string            `json:"startDate"`
+	EndDate         string            `json:"endDate"`
+	StartPage       int               `json:"startPage"`
+	EndPage         int               `json:"endPage"`
+	CreatedAt       time.Time         `json:"createdAt"`
+	Progress        float64           `json:"progress"`
+	PagesRead       float64           `json:"pagesRead"`
+	UnassignedPages int               `json:"unassignedPages"`
+	CompletedAt     *time.Time        `json:"completedAt"`
+	Assignments     []GroupAssignment `json:"assignments"`
+}
+
+// GroupAssignment is the part of a group target given to one member.
+// TargetID is the member's personal reading target. FirstJuz and LastJuz
+// are set when the range follows juz boundaries.
+type GroupAssignment struct {
+	ID            int     `json:"id"`
+	GroupTargetID int     `json:"groupTargetId"`
+	UserID        int     `json:"userId"`
+	Username      string  `json:"username"`
+	TargetID      int     `json:"targetId"`
+	StartPage     int     `json:"startPage"`
+	EndPage       int     `json:"endPage"`
+	FirstJuz      int     `json:"firstJuz,omitempty"`
+	LastJuz       int     `json:"lastJuz,omitempty"`
+	Status        string  `json:"status"`
+	Progress      float64 `json:"progress"`
+	PagesRead     float64 `json:"pagesRead"`
+}
+
+type GroupRequest struct {
+	Name string `json:"name"`
+}
+
+type GroupMemberRequest struct {
+	Username string `json:"username"`
+}
+
+// GroupTargetRequest creates a group target. Mode juz or pages splits the
+// range evenly between the members in joining order, manual takes the
+// Assignments as given.
+type GroupTargetRequest struct {
+	Name        string                   `json:"name"`
+	StartDate   string                   `json:"startDate"`
+	EndDate     string                   `json:"endDate"`
+	StartPage   int                      `json:"startPage"`
+	EndPage     int                      `json:"endPage"`
+	Mode        string                   `json:"mode"`
+	Assignments []GroupAssignmentRequest `json:"assignments"`
+}
+
+// GroupAssignmentRequest gives a page range, or a juz when Juz is set, to a
+// member.
+type GroupAssignmentRequest struct {
+	Username  string `json:"username"`
+	StartPage int    `json:"startPage"`
+	EndPage   int    `json:"endPage"`
+	Juz       int    `json:"juz"`
+}
+
+// GroupReassignRequest moves the unread pages of an assignment to another
+// member.
+type GroupReassignRequest struct {
+	Username string `json:"username"`
+}
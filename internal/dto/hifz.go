@@ -0,0 +1,55 @@
+package dto
+
+import "time"
+
+// HifzPage is a memorized mushaf page and its spaced repetition schedule.
+// FirstVerse and LastVerse span the memorized verses on the page.
+type HifzPage struct {
+	ID             int        `json:"id"`
+	UserID         int        `json:"userId"`
+	Page           int        `json:"page"`
+	FirstVerse     string     `json:"firstVerse"`
+	LastVerse      string     `json:"lastVerse"`
+	Repetitions    int        `json:"repetitions"`
+	IntervalDays   int        `json:"intervalDays"`
+	EaseFactor     float64    `json:"easeFactor"`
+	DueDate        string     `json:"dueDate"`
+	MemorizedAt    time.Time  `json:"memorizedAt"`
+	LastReviewedAt *time.Time `json:"lastReviewedAt"`
+}
+
+// HifzReview records how well a page was recited, Quality goes from 0
+// (forgotten) to 5 (perfect), and the schedule it led to.
+type HifzReview struct {
+	ID           int       `json:"id"`
+	UserID       int       `json:"userId"`
+	Page         int       `json:"page"`
+	Quality      int       `json:"quality"`
+	IntervalDays int       `json:"intervalDays"`
+	EaseFactor   float64   `json:"easeFactor"`
+	DueDate      string    `json:"dueDate"`
+	ReviewedAt   time.Time `json:"reviewedAt"`
+}
+
+// HifzMemorizeRequest marks either the page range StartPage to EndPage, the
+// listed Pages or the verses StartVerse to EndVerse, e.g. "2:1" to "2:20".
+type HifzMemorizeRequest struct {
+	StartPage  int    `json:"startPage"`
+	EndPage    int    `json:"endPage"`
+	Pages      []int  `json:"pages"`
+	StartVerse string `json:"startVerse"`
+	EndVerse   string `json:"endVerse"`
+}
+
+type HifzReviewRequest struct {
+	Page    int `json:"page"`
+	Quality int `json:"quality"`
+}
+
+// HifzDue lists the pages to revise on Date, overdue ones first.
+type HifzDue struct {
+	Timezone string     `json:"timezone"`
+	Date     string     `json:"date"`
+	Overdue  int        `json:"overdue"`
+	Pages    []HifzPage `json:"pages"`
+}
@@ -0,0 +1,12 @@
+package dto
+
+// HijriMonth is a month of the tabular Hijri calendar with its Gregorian
+// first and last day.
+type HijriMonth struct {
+	Year      int    `json:"year"`
+	Month     int    `json:"month"`
+	Name      string `json:"name"`
+	Days      int    `json:"days"`
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+import "time"
+
+// IdempotencyRecord is the stored response of a request sent with an
+// Idempotency-Key header. StatusCode is 0 while the request is in progress.
+type IdempotencyRecord struct {
+	Key         string
+	RequestHash string
+	StatusCode  int
+	Response    []byte
+	CreatedAt   time.Time
+}
@@ -0,0 +1,28 @@
+package dto
+
+import "time"
+
+// Khatam records a target whose whole page range was read. StartedAt is the
+// first reading of the target and CompletedAt the one that finished it,
+// DurationDays counts both days in the user's timezone. The target fields
+// are copied so the history outlives the target.
+type Khatam struct {
+	ID           int       `json:"id"`
+	UserID       int       `json:"userId"`
+	TargetID     int       `json:"targetId"`
+	TargetName   string    `json:"targetName"`
+	StartPage    int       `json:"startPage"`
+	EndPage      int       `json:"endPage"`
+	FullQuran    bool      `json:"fullQuran"`
+	StartedAt    time.Time `json:"startedAt"`
+	CompletedAt  time.Time `json:"completedAt"`
+	DurationDays int       `json:"durationDays"`
+}
+
+// KhatamHistory lists the completions of a user, newest first. FullQuran
+// counts the ones covering every page.
+type KhatamHistory struct {
+	Total     int      `json:"total"`
+	FullQuran int      `json:"fullQuran"`
+	Khatams   []Khatam `json:"khatams"`
+}
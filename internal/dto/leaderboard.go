@@ -4,6 +4,7 @@ import "time"
 
 type Leaderboard struct {
 	Type        string    `json:"type"`
+	Kind        string    `json:"kind"`
 	Ranks       []Rank    `json:"ranks"`
 	LastUpdated time.Time `json:"lastUpdated"`
 }
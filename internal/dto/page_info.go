@@ -1,15 +1,23 @@
 package dto
 
 type PageInfo struct {
+	PageNumber     int           `json:"pageNumber"`
+	FirstVerse     string        `json:"firstVerse"`
+	LastVerse      string        `json:"lastVerse"`
+	Juz            int           `json:"juz"`
+	Hizb           int           `json:"hizb"`
+	Manzil         int           `json:"manzil"`
 	NumberOfVerses int           `json:"numberOfVerses"`
 	AyatSajdah     int           `json:"ayatSajdah"`
 	Surahs         []ChapterInfo `json:"surahs"`
 }
 
 type ChapterInfo struct {
+	ID              int    `json:"id"`
 	Name            string `json:"name"`
+	NameArabic      string `json:"nameArabic"`
 	VersesCount     int    `json:"versesCount"`
 	RevelationPlace string `json:"revelation_place"`
-	Text            string `json:"text"`
-	SourceText      string `json:"sourceText"`
+	Text            string `json:"text,omitempty"`
+	SourceText      string `json:"sourceText,omitempty"`
 }
@@ -0,0 +1,39 @@
+package dto
+
+const (
+	ReadingPlanBehind    = "behind"
+	ReadingPlanOnTrack   = "on-track"
+	ReadingPlanAhead     = "ahead"
+	ReadingPlanCompleted = "completed"
+	ReadingPlanPlanned   = "planned"
+)
+
+// ReadingPlan splits the page range of a reading target into daily portions,
+// the days from today on are rescheduled from the pages still unread.
+type ReadingPlan struct {
+	TargetID       int     `json:"targetId"`
+	Date           string  `json:"date"`
+	StartDate      string  `json:"startDate"`
+	EndDate        string  `json:"endDate"`
+	TotalPages     int     `json:"totalPages"`
+	PagesRead      int     `json:"pagesRead"`
+	PagesRemaining int     `json:"pagesRemaining"`
+	DaysRemaining  int     `json:"daysRemaining"`
+	ExpectedRead   int     `json:"expectedRead"`
+	Status         string  `json:"status"`
+	DailyPages     float64 `json:"dailyPages"`
+	// CatchUpPages is how many pages behind the original schedule the reader is.
+	CatchUpPages int `json:"catchUpPages"`
+	// CatchUpDailyPages is the daily load needed to finish on the end date.
+	CatchUpDailyPages int              `json:"catchUpDailyPages"`
+	Days              []ReadingPlanDay `json:"days"`
+}
+
+type ReadingPlanDay struct {
+	Date      string `json:"date"`
+	StartPage int    `json:"startPage"`
+	EndPage   int    `json:"endPage"`
+	Pages     int    `json:"pages"`
+	PagesRead int    `json:"pagesRead"`
+	Status    string `json:"status"`
+}
@@ -1,16 +1,81 @@
 package dto
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/hijri"
+)
 
 type ReadingProgress struct {
 	ID          int       `json:"id"`
 	UserID      int       `json:"userId"`
 	TargetID    int       `json:"targetID"`
 	CurrentPage int       `json:"currentPage"`
+	// TimeStamp is when the server received the entry, ReadAt when the user
+	// says the page was read. ReadAt is the one used for statistics.
 	TimeStamp   time.Time `json:"timeStamp"`
+	ReadAt      time.Time `json:"readAt"`
+	// HijriReadAt is the Hijri day of ReadAt, filled when marshalling.
+	HijriReadAt string    `json:"hijriReadAt"`
+	// Kind is ProgressKindRead or ProgressKindReview, empty means read.
+	Kind        string    `json:"kind"`
+	// StartVerse and EndVerse, e.g. "2:142" and "2:150", log part of a page
+	// or a range over several pages starting on CurrentPage. Both are empty
+	// for a whole page.
+	StartVerse  string    `json:"startVerse"`
+	EndVerse    string    `json:"endVerse"`
+	// PageShare is how many pages the entry adds, 1 for a whole page.
+	PageShare   float64   `json:"pageShare"`
+}
+
+// MarshalJSON fills HijriReadAt from ReadAt.
+func (readingProgress ReadingProgress) MarshalJSON() ([]byte, error) {
+	type plainReadingProgress ReadingProgress
+	readingProgress.HijriReadAt = ""
+	if !readingProgress.ReadAt.IsZero() {
+		readingProgress.HijriReadAt = hijri.FromGregorian(readingProgress.ReadAt).String()
+	}
+	return json.Marshal(plainReadingProgress(readingProgress))
 }
 
+const (
+	// ProgressKindRead is the first reading of a page, it counts towards the
+	// target completion.
+	ProgressKindRead = "read"
+	// ProgressKindReview is a murajaah, a page read again.
+	ProgressKindReview = "review"
+)
+
 type ReadingProgressAggregated struct {
 	ReadingProgress []ReadingProgress `json:"readingProgress"`
 	ReadingProgressSorted map[int]map[string][]ReadingProgress `json:"readingProgressSorted"`
 }
+
+const (
+	BulkProgressCreated     = "created"
+	BulkProgressAlreadyRead = "already_read"
+	BulkProgressDuplicate   = "duplicate"
+)
+
+// BulkReadingProgressRequest logs either the page range StartPage to EndPage
+// or the listed Pages.
+type BulkReadingProgressRequest struct {
+	StartPage int       `json:"startPage"`
+	EndPage   int       `json:"endPage"`
+	Pages     []int     `json:"pages"`
+	ReadAt    time.Time `json:"readAt"`
+	Kind      string    `json:"kind"`
+}
+
+type BulkReadingProgressResult struct {
+	Created int                       `json:"created"`
+	Skipped int                       `json:"skipped"`
+	Pages   []BulkReadingProgressPage `json:"pages"`
+}
+
+type BulkReadingProgressPage struct {
+	Page       int    `json:"page"`
+	Status     string `json:"status"`
+	ProgressID int    `json:"progressId,omitempty"`
+}
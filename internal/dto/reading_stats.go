@@ -0,0 +1,61 @@
+package dto
+
+const (
+	StatsPeriodDay   = "day"
+	StatsPeriodWeek  = "week"
+	StatsPeriodMonth = "month"
+)
+
+// PeriodPages is the number of pages logged in the day, week or month that
+// starts on Date. Pages includes the ReviewPages.
+type PeriodPages struct {
+	Date        string  `json:"date"`
+	Pages       float64 `json:"pages"`
+	ReviewPages float64 `json:"reviewPages"`
+}
+
+// ReadingSummary aggregates all the progress of a user.
+type ReadingSummary struct {
+	TotalPages         float64
+	ReviewPages        float64
+	ActiveDays         int
+	FirstDay           string
+	BestDay            string
+	BestDayPages       float64
+	BestDayReviewPages float64
+}
+
+// TargetPages aggregates the first readings logged on one target.
+type TargetPages struct {
+	TargetID  int
+	PagesRead float64
+}
+
+type ReadingStats struct {
+	Timezone                 string             `json:"timezone"`
+	Today                    string             `json:"today"`
+	TotalPages               float64            `json:"totalPages"`
+	ReviewPages              float64            `json:"reviewPages"`
+	ActiveDays               int                `json:"activeDays"`
+	AveragePagesPerDay       float64            `json:"averagePagesPerDay"`
+	AveragePagesPerActiveDay float64            `json:"averagePagesPerActiveDay"`
+	BestDay                  *PeriodPages       `json:"bestDay"`
+	Daily                    []PeriodPages      `json:"daily"`
+	Weekly                   []PeriodPages      `json:"weekly"`
+	Monthly                  []PeriodPages      `json:"monthly"`
+	Heatmap                  []PeriodPages      `json:"heatmap"`
+	Targets                  []TargetProjection `json:"targets"`
+}
+
+// TargetProjection estimates when an active target is finished at the pace
+// kept since it started.
+type TargetProjection struct {
+	TargetID      int     `json:"targetId"`
+	Name          string  `json:"name"`
+	EndDate       string  `json:"endDate"`
+	TotalPages    int     `json:"totalPages"`
+	PagesRead     float64 `json:"pagesRead"`
+	PagesPerDay   float64 `json:"pagesPerDay"`
+	ProjectedDate string  `json:"projectedDate,omitempty"`
+	OnSchedule    bool    `json:"onSchedule"`
+}
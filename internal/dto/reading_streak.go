@@ -0,0 +1,23 @@
+package dto
+
+type ReadingStreaks struct {
+	Timezone  string `json:"timezone"`
+	GraceDays int    `json:"graceDays"`
+	Today     string `json:"today"`
+	ReadToday bool   `json:"readToday"`
+	// CurrentStreak is 0 when the last streak has already been broken.
+	CurrentStreak int             `json:"currentStreak"`
+	LongestStreak int             `json:"longestStreak"`
+	Current       *ReadingStreak  `json:"current"`
+	Longest       *ReadingStreak  `json:"longest"`
+	History       []ReadingStreak `json:"history"`
+}
+
+// ReadingStreak is a run of reading days, Days counts the days read and
+// GraceDaysUsed the missed days the streak survived.
+type ReadingStreak struct {
+	StartDate     string `json:"startDate"`
+	EndDate       string `json:"endDate"`
+	Days          int    `json:"days"`
+	GraceDaysUsed int    `json:"graceDaysUsed"`
+}
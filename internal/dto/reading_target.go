@@ -1,18 +1,98 @@
 package dto
 
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/hijri"
+)
+
+// A target is active until it is paused, fully read or past its end date.
+// Only active, paused, completed and reassigned are stored, expired is
+// derived from the end date so moving the end date revives the target. A
+// group assignment target handed to another member is reassigned and is
+// never completed.
+const (
+	TargetStatusActive     = "active"
+	TargetStatusPaused     = "paused"
+	TargetStatusCompleted  = "completed"
+	TargetStatusExpired    = "expired"
+	TargetStatusReassigned = "reassigned"
+)
+
+// A recurring target gets a next instance once its end date has passed.
+// Weekly and monthly move the dates by a week or a month, ramadan keeps
+// the same Hijri dates a year later.
+const (
+	RecurrenceWeekly  = "weekly"
+	RecurrenceMonthly = "monthly"
+	RecurrenceRamadan = "ramadan"
+)
+
+// ReadingTarget is a page range to read between two dates. The Hijri dates
+// can be given instead of StartDate and EndDate when creating a target, in
+// responses they always follow the Gregorian ones. A Scope given instead of
+// StartPage and EndPage is resolved to its page range and kept, Label names
+// it in responses. PreviousID is the target a recurrence created this one
+// from and NextID the instance created after it.
 type ReadingTarget struct {
-	ID               int     `json:"id"`
-	Name             string  `json:"name"`
-	UserID           int     `json:"userId"`
-	StartDate        string  `json:"startDate"`
-	EndDate          string  `json:"endDate"`
-	StartPage        int     `json:"startPage"`
-	EndPage          int     `json:"endPage"`
-	Pages            float64 `json:"pages"`
-	Progress         float64 `json:"progress"`
-	LastReadPage     int     `json:"lastReadPage"`
-	GoogleCalendarID string  `json:"-"`
-	IsPublic         bool    `json:"isPublic"`
+	ID               int          `json:"id"`
+	Name             string       `json:"name"`
+	UserID           int          `json:"userId"`
+	StartDate        string       `json:"startDate"`
+	EndDate          string       `json:"endDate"`
+	HijriStartDate   string       `json:"hijriStartDate"`
+	HijriEndDate     string       `json:"hijriEndDate"`
+	StartPage        int          `json:"startPage"`
+	EndPage          int          `json:"endPage"`
+	Scope            *TargetScope `json:"scope"`
+	Label            string       `json:"label"`
+	Pages            float64      `json:"pages"`
+	Progress         float64      `json:"progress"`
+	LastReadPage     int          `json:"lastReadPage"`
+	GoogleCalendarID string       `json:"-"`
+	IsPublic         bool         `json:"isPublic"`
+	Status           string       `json:"status"`
+	PausedAt         *time.Time   `json:"pausedAt"`
+	PausedDays       int          `json:"pausedDays"`
+	Recurrence       string       `json:"recurrence"`
+	CarryOver        bool         `json:"carryOver"`
+	PreviousID       *int         `json:"previousId"`
+	NextID           *int         `json:"nextId"`
+	CalendarSync     CalendarSync `json:"calendarSync"`
+	// CompletedAt is when the last page of the range was read, nil while
+	// the target is not complete.
+	CompletedAt *time.Time `json:"completedAt"`
+}
+
+// MarshalJSON fills the Hijri dates from StartDate and EndDate and the
+// label from the scope.
+func (readingTarget ReadingTarget) MarshalJSON() ([]byte, error) {
+	type plainReadingTarget ReadingTarget
+	readingTarget.Label = TargetLabel(readingTarget.Scope, readingTarget.StartPage, readingTarget.EndPage)
+	readingTarget.HijriStartDate = hijriDate(readingTarget.StartDate)
+	readingTarget.HijriEndDate = hijriDate(readingTarget.EndDate)
+	return json.Marshal(plainReadingTarget(readingTarget))
+}
+
+// hijriDate converts a YYYY-MM-DD date, the driver may add a time part to
+// it. It returns an empty string for a date it can not read.
+func hijriDate(date string) string {
+	if len(date) > len("2006-01-02") {
+		date = date[:len("2006-01-02")]
+	}
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return ""
+	}
+	return hijri.FromGregorian(t).String()
+}
+
+// CalendarSync is the result of the last Google Calendar sync of a target.
+type CalendarSync struct {
+	Status   string     `json:"status"`
+	Error    string     `json:"error,omitempty"`
+	SyncedAt *time.Time `json:"syncedAt"`
 }
 
 type ReadingTargetWithUser struct {
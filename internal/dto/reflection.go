@@ -0,0 +1,26 @@
+package dto
+
+import "time"
+
+// Reflection is a tadabbur note attached to a progress entry. ProgressID is
+// 0 once the entry is deleted, Page keeps the page it was written on.
+type Reflection struct {
+	ID         int       `json:"id"`
+	UserID     int       `json:"userId"`
+	ProgressID int       `json:"progressId"`
+	Page       int       `json:"page"`
+	VerseKey   string    `json:"verseKey"`
+	Note       string    `json:"note"`
+	Tags       []string  `json:"tags"`
+	IsPublic   bool      `json:"isPublic"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// ReflectionFilter narrows the notes of a user, empty fields match all.
+// Query is a full-text search over the note and its tags.
+type ReflectionFilter struct {
+	Query      string
+	Tag        string
+	ProgressID int
+}
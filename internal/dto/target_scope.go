@@ -0,0 +1,91 @@
+package dto
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/quran"
+)
+
+// TargetScope is the part of the Quran a target was created for, kept so
+// the target can be shown the way it was asked for. Juz, Surah and Hizb are
+// a number or a [first, last] pair, Verses a "2:1-2:141" range. Only one of
+// them is set.
+type TargetScope struct {
+	Juz    ScopeRange `json:"juz,omitempty"`
+	Surah  ScopeRange `json:"surah,omitempty"`
+	Hizb   ScopeRange `json:"hizb,omitempty"`
+	Verses string     `json:"verses,omitempty"`
+}
+
+// ScopeRange holds one number or a first and last number.
+type ScopeRange []int
+
+// UnmarshalJSON reads either 18 or [18, 20].
+func (scopeRange *ScopeRange) UnmarshalJSON(data []byte) error {
+	var number int
+	if err := json.Unmarshal(data, &number); err == nil {
+		*scopeRange = ScopeRange{number}
+		return nil
+	}
+	var numbers []int
+	if err := json.Unmarshal(data, &numbers); err != nil {
+		return fmt.Errorf("scope range must be a number or a [first, last] pair")
+	}
+	*scopeRange = numbers
+	return nil
+}
+
+// MarshalJSON writes a single number back as a number.
+func (scopeRange ScopeRange) MarshalJSON() ([]byte, error) {
+	if len(scopeRange) == 1 {
+		return json.Marshal(scopeRange[0])
+	}
+	return json.Marshal([]int(scopeRange))
+}
+
+// Bounds returns the first and last number of the range.
+func (scopeRange ScopeRange) Bounds() (int, int) {
+	if len(scopeRange) == 0 {
+		return 0, 0
+	}
+	return scopeRange[0], scopeRange[len(scopeRange)-1]
+}
+
+// TargetLabel names what a target covers, e.g. "Juz 1–5" or
+// "Surah Al-Kahf", and "Halaman 1 - 101" when it has no scope.
+func TargetLabel(scope *TargetScope, startPage, endPage int) string {
+	switch {
+	case scope == nil:
+	case len(scope.Juz) > 0:
+		return "Juz " + rangeLabel(scope.Juz.Bounds())
+	case len(scope.Hizb) > 0:
+		return "Hizb " + rangeLabel(scope.Hizb.Bounds())
+	case len(scope.Surah) > 0:
+		first, last := scope.Surah.Bounds()
+		label := "Surah " + surahName(first)
+		if last != first {
+			label += " – " + surahName(last)
+		}
+		return label
+	case scope.Verses != "":
+		return "Ayat " + strings.Replace(scope.Verses, "-", "–", 1)
+	}
+	return fmt.Sprintf("Halaman %d - %d", startPage, endPage)
+}
+
+func rangeLabel(first, last int) string {
+	if first == last {
+		return fmt.Sprint(first)
+	}
+	return fmt.Sprintf("%d–%d", first, last)
+}
+
+func surahName(number int) string {
+	surah, err := quran.GetSurah(number)
+	if err != nil {
+		return fmt.Sprint(number)
+	}
+	return surah.Name
+}
@@ -0,0 +1,29 @@
+package dto
+
+import "time"
+
+// TargetTemplate is a ready made reading target. A target made from it
+// starts on the chosen date, moved forward to Weekday when one is set (0 is
+// Sunday), and lasts DurationDays days.
+type TargetTemplate struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	StartPage    int       `json:"startPage"`
+	EndPage      int       `json:"endPage"`
+	Pages        float64   `json:"pages"`
+	DurationDays int       `json:"durationDays"`
+	Weekday      *int      `json:"weekday"`
+	Recurrence   string    `json:"recurrence"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// TargetFromTemplateRequest makes a target from a template. HijriStartDate
+// can be given instead of StartDate, Name defaults to the template name.
+type TargetFromTemplateRequest struct {
+	StartDate      string `json:"startDate"`
+	HijriStartDate string `json:"hijriStartDate"`
+	Name           string `json:"name"`
+	IsPublic       bool   `json:"isPublic"`
+	CarryOver      bool   `json:"carryOver"`
+}
@@ -7,6 +7,9 @@ type User struct {
 	Password    string `json:"password"`
 	DisplayName string `json:"displayName"`
 	GoogleToken string `json:"-"`
+	// Timezone is an IANA name like "Asia/Jakarta", empty means DEFAULT_TIMEZONE.
+	Timezone        string `json:"timezone"`
+	StreakGraceDays int    `json:"streakGraceDays"`
 }
 
 type LoginRequest struct {
@@ -0,0 +1,108 @@
+package hijri
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	Ramadan = 9
+
+	// epoch is the Julian day number of 1 Muharram 1 AH in the civil
+	// tabular calendar.
+	epoch = 1948440
+	// unixEpoch is the Julian day number of 1970-01-01.
+	unixEpoch = 2440588
+)
+
+// ErrInvalidDate is returned for a month or day outside the calendar.
+var ErrInvalidDate = errors.New("invalid hijri date")
+
+var monthNames = [12]string{
+	"Muharram", "Safar", "Rabiulawal", "Rabiulakhir", "Jumadilawal", "Jumadilakhir",
+	"Rajab", "Syakban", "Ramadan", "Syawal", "Zulkaidah", "Zulhijah",
+}
+
+// Date is a day of the tabular Islamic calendar. Odd months have 30 days
+// and even months 29, Dhul Hijjah has 30 in the 11 leap years of every 30
+// year cycle. The tabular calendar can be a day away from the sighted one.
+type Date struct {
+	Year  int
+	Month int
+	Day   int
+}
+
+// FromGregorian returns the Hijri date of the calendar day of t.
+func FromGregorian(t time.Time) Date {
+	jdn := julianDay(t)
+	year := (30*(jdn-epoch) + 10646) / 10631
+	month := 2*(jdn-dayNumber(year, 1, 1))/59 + 1
+	if month > 12 {
+		month = 12
+	}
+	return Date{Year: year, Month: month, Day: jdn - dayNumber(year, month, 1) + 1}
+}
+
+// Parse reads a date written as YYYY-MM-DD.
+func Parse(value string) (Date, error) {
+	var d Date
+	if _, err := fmt.Sscanf(value, "%d-%d-%d", &d.Year, &d.Month, &d.Day); err != nil {
+		return Date{}, ErrInvalidDate
+	}
+	if fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day) != value || !d.Valid() {
+		return Date{}, ErrInvalidDate
+	}
+	return d, nil
+}
+
+// String formats d as YYYY-MM-DD.
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// Valid reports whether d is a day of the calendar.
+func (d Date) Valid() bool {
+	return d.Year >= 1 && d.Month >= 1 && d.Month <= 12 && d.Day >= 1 && d.Day <= MonthLength(d.Year, d.Month)
+}
+
+// MonthName returns the Indonesian name of month, empty outside 1 to 12.
+func MonthName(month int) string {
+	if month < 1 || month > 12 {
+		return ""
+	}
+	return monthNames[month-1]
+}
+
+// MonthRange returns the Gregorian days of the first and last day of month
+// of year.
+func MonthRange(year, month int) (time.Time, time.Time, error) {
+	if year < 1 || month < 1 || month > 12 {
+		return time.Time{}, time.Time{}, ErrInvalidDate
+	}
+	first := ToGregorian(Date{Year: year, Month: month, Day: 1})
+	return first, first.AddDate(0, 0, MonthLength(year, month)-1), nil
+}
+
+// ToGregorian returns the Gregorian day of d as a UTC midnight.
+func ToGregorian(d Date) time.Time {
+	days := dayNumber(d.Year, d.Month, d.Day) - unixEpoch
+	return time.Date(1970, 1, 1+days, 0, 0, 0, 0, time.UTC)
+}
+
+// MonthLength returns the number of days in month of year.
+func MonthLength(year, month int) int {
+	if month == 12 {
+		return dayNumber(year+1, 1, 1) - dayNumber(year, 12, 1)
+	}
+	return dayNumber(year, month+1, 1) - dayNumber(year, month, 1)
+}
+
+func dayNumber(year, month, day int) int {
+	return day + (59*(month-1)+1)/2 + (year-1)*354 + (3+11*year)/30 + epoch - 1
+}
+
+func julianDay(t time.Time) int {
+	year, month, day := t.Date()
+	return int(time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix()/86400) + unixEpoch
+}
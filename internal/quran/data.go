@@ -0,0 +1,149 @@
+package quran
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+)
+
+// metadata.json follows the Tanzil quran-data layout for the Madani mushaf:
+// every division is listed by its first verse as a [surah, verse] pair.
+//
+//go:embed data/metadata.json
+var metadataJSON []byte
+
+type rawMetadata struct {
+	Surahs       []Surah  `json:"surahs"`
+	Juz          [][2]int `json:"juz"`
+	HizbQuarters [][2]int `json:"hizbQuarters"`
+	Manzils      [][2]int `json:"manzils"`
+	Rukus        [][2]int `json:"rukus"`
+	Pages        [][2]int `json:"pages"`
+	Sajdahs      []struct {
+		Verse [2]int `json:"verse"`
+		Type  string `json:"type"`
+	} `json:"sajdahs"`
+}
+
+var (
+	surahs []Surah
+	// verses is indexed by the verse position in the mushaf, starting at 0.
+	verses []Verse
+	// surahOffsets holds the position of the first verse of every surah.
+	surahOffsets []int
+	// pageOffsets holds the position of the first verse of every page, plus
+	// TotalVerses as the end of the last page.
+	pageOffsets []int
+	// juzOffsets and hizbOffsets do the same for every juz and hizb.
+	juzOffsets  []int
+	hizbOffsets []int
+)
+
+func init() {
+	if err := load(metadataJSON); err != nil {
+		panic("quran: invalid embedded metadata: " + err.Error())
+	}
+}
+
+func load(data []byte) error {
+	var raw rawMetadata
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	if len(raw.Surahs) != TotalSurahs || len(raw.Pages) != TotalPages || len(raw.Juz) != TotalJuz ||
+		len(raw.HizbQuarters) != TotalHizb*4 || len(raw.Manzils) != TotalManzils {
+		return fmt.Errorf("unexpected division count")
+	}
+
+	surahs = raw.Surahs
+	surahOffsets = make([]int, TotalSurahs+1)
+	for i, surah := range surahs {
+		surahOffsets[i+1] = surahOffsets[i] + surah.VersesCount
+	}
+	if surahOffsets[TotalSurahs] != TotalVerses {
+		return fmt.Errorf("expected %d verses, got %d", TotalVerses, surahOffsets[TotalSurahs])
+	}
+
+	verses = make([]Verse, TotalVerses)
+	for i := range surahs {
+		for number := 1; number <= surahs[i].VersesCount; number++ {
+			position := surahOffsets[i] + number - 1
+			verses[position] = Verse{Key: VerseKey(i+1, number), Surah: i + 1, Number: number}
+		}
+	}
+
+	assign := func(starts [][2]int, set func(verse *Verse, division int)) error {
+		division := 0
+		for position := range verses {
+			for division < len(starts) && verseOffset(starts[division]) == position {
+				division++
+			}
+			if division == 0 {
+				return fmt.Errorf("division does not start at 1:1")
+			}
+			set(&verses[position], division)
+		}
+		if division != len(starts) {
+			return fmt.Errorf("divisions are not in mushaf order")
+		}
+		return nil
+	}
+
+	if err := assign(raw.Pages, func(v *Verse, n int) { v.Page = n }); err != nil {
+		return fmt.Errorf("pages: %w", err)
+	}
+	if err := assign(raw.Juz, func(v *Verse, n int) { v.Juz = n }); err != nil {
+		return fmt.Errorf("juz: %w", err)
+	}
+	if err := assign(raw.HizbQuarters, func(v *Verse, n int) { v.RubElHizb = n; v.Hizb = (n-1)/4 + 1 }); err != nil {
+		return fmt.Errorf("hizb: %w", err)
+	}
+	if err := assign(raw.Manzils, func(v *Verse, n int) { v.Manzil = n }); err != nil {
+		return fmt.Errorf("manzil: %w", err)
+	}
+	if err := assign(raw.Rukus, func(v *Verse, n int) { v.Ruku = n }); err != nil {
+		return fmt.Errorf("ruku: %w", err)
+	}
+
+	for _, sajdah := range raw.Sajdahs {
+		position := verseOffset(sajdah.Verse)
+		if position < 0 {
+			return fmt.Errorf("invalid sajdah verse %v", sajdah.Verse)
+		}
+		verses[position].Sajdah = sajdah.Type
+	}
+
+	pageOffsets = make([]int, 0, TotalPages+1)
+	for _, start := range raw.Pages {
+		pageOffsets = append(pageOffsets, verseOffset(start))
+	}
+	pageOffsets = append(pageOffsets, TotalVerses)
+
+	juzOffsets = make([]int, 0, TotalJuz+1)
+	for _, start := range raw.Juz {
+		juzOffsets = append(juzOffsets, verseOffset(start))
+	}
+	juzOffsets = append(juzOffsets, TotalVerses)
+
+	hizbOffsets = make([]int, 0, TotalHizb+1)
+	for i := 0; i < len(raw.HizbQuarters); i += 4 {
+		hizbOffsets = append(hizbOffsets, verseOffset(raw.HizbQuarters[i]))
+	}
+	hizbOffsets = append(hizbOffsets, TotalVerses)
+
+	for i := range surahs {
+		surahs[i].FirstPage = verses[surahOffsets[i]].Page
+		surahs[i].LastPage = verses[surahOffsets[i+1]-1].Page
+	}
+	return nil
+}
+
+// verseOffset returns the mushaf position of a [surah, verse] pair, or -1.
+func verseOffset(ref [2]int) int {
+	surah, verse := ref[0], ref[1]
+	if surah < 1 || surah > len(surahs) || verse < 1 || verse > surahs[surah-1].VersesCount {
+		return -1
+	}
+	return surahOffsets[surah-1] + verse - 1
+}
@@ -0,0 +1,156 @@
+package quran
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	TotalPages   = 604
+	TotalSurahs  = 114
+	TotalVerses  = 6236
+	TotalJuz     = 30
+	TotalHizb    = 60
+	TotalManzils = 7
+)
+
+// ErrInvalidReference is returned for a page, surah or verse outside the mushaf.
+var ErrInvalidReference = errors.New("invalid quran reference")
+
+type Surah struct {
+	Number          int    `json:"number"`
+	Name            string `json:"name"`
+	NameArabic      string `json:"nameArabic"`
+	NameEnglish     string `json:"nameEnglish"`
+	RevelationPlace string `json:"revelationPlace"`
+	RevelationOrder int    `json:"revelationOrder"`
+	VersesCount     int    `json:"versesCount"`
+	Rukus           int    `json:"rukus"`
+	FirstPage       int    `json:"firstPage"`
+	LastPage        int    `json:"lastPage"`
+}
+
+type Verse struct {
+	Key       string `json:"key"`
+	Surah     int    `json:"surah"`
+	Number    int    `json:"number"`
+	Page      int    `json:"page"`
+	Juz       int    `json:"juz"`
+	Hizb      int    `json:"hizb"`
+	RubElHizb int    `json:"rubElHizb"`
+	Ruku      int    `json:"ruku"`
+	Manzil    int    `json:"manzil"`
+	// Sajdah is "recommended" or "obligatory" for a verse of prostration.
+	Sajdah string `json:"sajdah,omitempty"`
+}
+
+// VerseKey formats a verse reference as "surah:verse", e.g. "2:255".
+func VerseKey(surah, verse int) string {
+	return strconv.Itoa(surah) + ":" + strconv.Itoa(verse)
+}
+
+// ParseVerseKey parses a "surah:verse" reference and checks it exists.
+func ParseVerseKey(key string) (int, int, error) {
+	parts := strings.Split(strings.TrimSpace(key), ":")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("%w: verse key %q", ErrInvalidReference, key)
+	}
+	surah, errSurah := strconv.Atoi(parts[0])
+	verse, errVerse := strconv.Atoi(parts[1])
+	if errSurah != nil || errVerse != nil || verseOffset([2]int{surah, verse}) < 0 {
+		return 0, 0, fmt.Errorf("%w: verse key %q", ErrInvalidReference, key)
+	}
+	return surah, verse, nil
+}
+
+// GetSurah returns a surah by its number, 1 to 114.
+func GetSurah(number int) (Surah, error) {
+	if number < 1 || number > TotalSurahs {
+		return Surah{}, fmt.Errorf("%w: surah %d", ErrInvalidReference, number)
+	}
+	return surahs[number-1], nil
+}
+
+// GetSurahs returns every surah in mushaf order.
+func GetSurahs() []Surah {
+	result := make([]Surah, len(surahs))
+	copy(result, surahs)
+	return result
+}
+
+// GetVerse returns a verse by surah and verse number.
+func GetVerse(surah, verse int) (Verse, error) {
+	position := verseOffset([2]int{surah, verse})
+	if position < 0 {
+		return Verse{}, fmt.Errorf("%w: verse %d:%d", ErrInvalidReference, surah, verse)
+	}
+	return verses[position], nil
+}
+
+// GetVerseByKey returns a verse by its "surah:verse" key.
+func GetVerseByKey(key string) (Verse, error) {
+	surah, verse, err := ParseVerseKey(key)
+	if err != nil {
+		return Verse{}, err
+	}
+	return GetVerse(surah, verse)
+}
+
+// GetPageVerses returns the verses printed on a mushaf page, 1 to 604.
+func GetPageVerses(page int) ([]Verse, error) {
+	if page < 1 || page > TotalPages {
+		return nil, fmt.Errorf("%w: page %d", ErrInvalidReference, page)
+	}
+	result := make([]Verse, pageOffsets[page]-pageOffsets[page-1])
+	copy(result, verses[pageOffsets[page-1]:pageOffsets[page]])
+	return result, nil
+}
+
+// GetJuzPages returns the first and last page of a juz, 1 to 30.
+func GetJuzPages(juz int) (int, int, error) {
+	if juz < 1 || juz > TotalJuz {
+		return 0, 0, fmt.Errorf("%w: juz %d", ErrInvalidReference, juz)
+	}
+	return verses[juzOffsets[juz-1]].Page, verses[juzOffsets[juz]-1].Page, nil
+}
+
+// GetHizbPages returns the first and last page of a hizb, 1 to 60.
+func GetHizbPages(hizb int) (int, int, error) {
+	if hizb < 1 || hizb > TotalHizb {
+		return 0, 0, fmt.Errorf("%w: hizb %d", ErrInvalidReference, hizb)
+	}
+	return verses[hizbOffsets[hizb-1]].Page, verses[hizbOffsets[hizb]-1].Page, nil
+}
+
+// GetVerseRange returns the verses from start to end inclusive, both given as
+// "surah:verse" keys, in mushaf order.
+func GetVerseRange(start, end string) ([]Verse, error) {
+	startSurah, startVerse, err := ParseVerseKey(start)
+	if err != nil {
+		return nil, err
+	}
+	endSurah, endVerse, err := ParseVerseKey(end)
+	if err != nil {
+		return nil, err
+	}
+	from, to := verseOffset([2]int{startSurah, startVerse}), verseOffset([2]int{endSurah, endVerse})
+	if to < from {
+		return nil, fmt.Errorf("%w: verse %s is after %s", ErrInvalidReference, start, end)
+	}
+	result := make([]Verse, to-from+1)
+	copy(result, verses[from:to+1])
+	return result, nil
+}
+
+// GetSajdahVerses returns the verses of prostration in mushaf order.
+func GetSajdahVerses() []Verse {
+	result := make([]Verse, 0)
+	for _, verse := range verses {
+		if verse.Sajdah != "" {
+			result = append(result, verse)
+		}
+	}
+	return result
+}
@@ -0,0 +1,1298 @@
+package storage
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/dto"
+)
+
+// NewMemoryStore returns a Store that keeps everything in process memory.
+// It is meant for tests and local experiments, nothing is persisted.
+func NewMemoryStore() Store {
+	outbox := &memoryCalendarOutboxStore{entries: make(map[int]dto.CalendarOutbox), lockedUntil: make(map[int]time.Time)}
+	progress := &memoryReadingProgressStore{progresses: make(map[int]dto.ReadingProgress)}
+	users := &memoryUserStore{users: make(map[int]dto.User), admins: make(map[int]dto.Admin)}
+	targets := &memoryReadingTargetStore{targets: make(map[int]dto.ReadingTarget), outbox: outbox}
+	outbox.targets = targets
+	return Store{
+		Users:           users,
+		ReadingTargets:  targets,
+		ReadingProgress: progress,
+		CalendarOutbox:  outbox,
+		Idempotency:     &memoryIdempotencyStore{records: make(map[string]dto.IdempotencyRecord)},
+		ReadingStats:    &memoryReadingStatsStore{progress: progress},
+		Hifz:            &memoryHifzStore{pages: make(map[[2]int]dto.HifzPage)},
+		Bookmarks:       &memoryBookmarkStore{bookmarks: make(map[int]dto.BookmarkPin)},
+		Reflections:     &memoryReflectionStore{reflections: make(map[int]dto.Reflection), progress: progress},
+		Khatams:         &memoryKhatamStore{khatams: make(map[int]dto.Khatam)},
+		Groups: &memoryGroupStore{
+			groups:         make(map[int]dto.Group),
+			groupTargets:   make(map[int]dto.GroupTarget),
+			users:          users,
+			readingTargets: targets,
+		},
+		Templates: &memoryTargetTemplateStore{templates: make(map[int]dto.TargetTemplate)},
+	}
+}
+
+type memoryUserStore struct {
+	mu     sync.RWMutex
+	nextID int
+	users  map[int]dto.User
+	admins map[int]dto.Admin
+}
+
+func (s *memoryUserStore) GetAll() ([]dto.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := make([]dto.User, 0, len(s.users))
+	for _, user := range s.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+func (s *memoryUserStore) GetByID(id int) (dto.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return dto.User{}, ErrNotFound
+	}
+	return user, nil
+}
+
+func (s *memoryUserStore) find(match func(dto.User) bool) (dto.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, user := range s.users {
+		if match(user) {
+			return user, nil
+		}
+	}
+	return dto.User{}, ErrNotFound
+}
+
+func (s *memoryUserStore) GetByUsername(username string) (dto.User, error) {
+	return s.find(func(user dto.User) bool { return user.Username == username })
+}
+
+func (s *memoryUserStore) GetByEmail(email string) (dto.User, error) {
+	return s.find(func(user dto.User) bool { return user.Email == email })
+}
+
+func (s *memoryUserStore) Create(user *dto.User, hashedPassword string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	user.ID = s.nextID
+	stored := *user
+	stored.Password = hashedPassword
+	stored.DisplayName = user.Email
+	s.users[user.ID] = stored
+	return nil
+}
+
+func (s *memoryUserStore) Update(user dto.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.users[user.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	stored.Username = user.Username
+	stored.Email = user.Email
+	stored.GoogleToken = user.GoogleToken
+	stored.DisplayName = user.DisplayName
+	stored.Timezone = user.Timezone
+	stored.StreakGraceDays = user.StreakGraceDays
+	s.users[user.ID] = stored
+	return nil
+}
+
+func (s *memoryUserStore) Delete(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.users, id)
+	return nil
+}
+
+func (s *memoryUserStore) GetAdminByUsername(username string) (dto.Admin, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, admin := range s.admins {
+		if admin.Username == username {
+			return admin, nil
+		}
+	}
+	return dto.Admin{}, ErrNotFound
+}
+
+type memoryReadingTargetStore struct {
+	mu      sync.RWMutex
+	nextID  int
+	targets map[int]dto.ReadingTarget
+	outbox  *memoryCalendarOutboxStore
+}
+
+func (s *memoryReadingTargetStore) filter(match func(dto.ReadingTarget) bool) []dto.ReadingTarget {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var readingTargets []dto.ReadingTarget
+	for _, readingTarget := range s.targets {
+		if match(readingTarget) {
+			readingTargets = append(readingTargets, readingTarget)
+		}
+	}
+	sort.Slice(readingTargets, func(i, j int) bool { return readingTargets[i].ID < readingTargets[j].ID })
+	return readingTargets
+}
+
+func (s *memoryReadingTargetStore) GetAll() ([]dto.ReadingTarget, error) {
+	return s.filter(func(dto.ReadingTarget) bool { return true }), nil
+}
+
+func (s *memoryReadingTargetStore) GetByID(id int) (dto.ReadingTarget, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	readingTarget, ok := s.targets[id]
+	if !ok {
+		return dto.ReadingTarget{}, ErrNotFound
+	}
+	return readingTarget, nil
+}
+
+func (s *memoryReadingTargetStore) GetByUserID(userID int) ([]dto.ReadingTarget, error) {
+	return s.filter(func(rt dto.ReadingTarget) bool { return rt.UserID == userID }), nil
+}
+
+func (s *memoryReadingTargetStore) GetPublic() ([]dto.ReadingTarget, error) {
+	return s.filter(func(rt dto.ReadingTarget) bool { return rt.IsPublic }), nil
+}
+
+func (s *memoryReadingTargetStore) Create(readingTarget *dto.ReadingTarget) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	readingTarget.ID = s.nextID
+	if readingTarget.Status == "" {
+		readingTarget.Status = dto.TargetStatusActive
+	}
+	s.store(*readingTarget)
+	return nil
+}
+
+func (s *memoryReadingTargetStore) Update(readingTarget dto.ReadingTarget) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.update(readingTarget)
+}
+
+func (s *memoryReadingTargetStore) update(readingTarget dto.ReadingTarget) error {
+	stored, ok := s.targets[readingTarget.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	readingTarget.GoogleCalendarID = stored.GoogleCalendarID
+	readingTarget.CalendarSync = stored.CalendarSync
+	readingTarget.PreviousID = stored.PreviousID
+	readingTarget.NextID = stored.NextID
+	readingTarget.Scope = stored.Scope
+	if readingTarget.Status == "" {
+		readingTarget.Status = stored.Status
+	}
+	s.targets[readingTarget.ID] = readingTarget
+	return nil
+}
+
+// store saves a new target with its own copy of the scope, like the scope
+// column does.
+func (s *memoryReadingTargetStore) store(readingTarget dto.ReadingTarget) {
+	if readingTarget.Scope != nil {
+		scope := *readingTarget.Scope
+		readingTarget.Scope = &scope
+	}
+	s.targets[readingTarget.ID] = readingTarget
+}
+
+func (s *memoryReadingTargetStore) Delete(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.targets, id)
+	return nil
+}
+
+func (s *memoryReadingTargetStore) CreateWithCalendarSync(readingTarget *dto.ReadingTarget) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.createWithCalendarSync(readingTarget)
+	return nil
+}
+
+func (s *memoryReadingTargetStore) createWithCalendarSync(readingTarget *dto.ReadingTarget) {
+	s.nextID++
+	readingTarget.ID = s.nextID
+	if readingTarget.Status == "" {
+		readingTarget.Status = dto.TargetStatusActive
+	}
+	readingTarget.CalendarSync = dto.CalendarSync{Status: dto.CalendarSyncPending}
+	s.store(*readingTarget)
+	s.outbox.enqueue(*readingTarget, "ADD")
+}
+
+func (s *memoryReadingTargetStore) UpdateWithCalendarSync(readingTarget dto.ReadingTarget) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.update(readingTarget); err != nil {
+		return err
+	}
+	stored := s.targets[readingTarget.ID]
+	stored.CalendarSync.Status = dto.CalendarSyncPending
+	s.targets[readingTarget.ID] = stored
+	s.outbox.enqueue(stored, "EDIT")
+	return nil
+}
+
+func (s *memoryReadingTargetStore) DeleteWithCalendarSync(readingTarget dto.ReadingTarget) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.targets[readingTarget.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	delete(s.targets, readingTarget.ID)
+	s.outbox.enqueue(stored, "DELETE")
+	return nil
+}
+
+func (s *memoryReadingTargetStore) UpdateCalendarSync(targetID int, googleCalendarID string, calendarSync dto.CalendarSync) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.targets[targetID]
+	if !ok {
+		return nil
+	}
+	if googleCalendarID != "" {
+		stored.GoogleCalendarID = googleCalendarID
+	}
+	if calendarSync.SyncedAt == nil {
+		calendarSync.SyncedAt = stored.CalendarSync.SyncedAt
+	}
+	stored.CalendarSync = calendarSync
+	s.targets[targetID] = stored
+	return nil
+}
+
+// markReassigned sets the status of a group assignment target handed to
+// another member.
+func (s *memoryReadingTargetStore) markReassigned(targetID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.targets[targetID]
+	if !ok {
+		return
+	}
+	stored.Status = dto.TargetStatusReassigned
+	stored.PausedAt = nil
+	s.targets[targetID] = stored
+}
+
+func (s *memoryReadingTargetStore) GetRecurring() ([]dto.ReadingTarget, error) {
+	return s.filter(func(rt dto.ReadingTarget) bool { return rt.Recurrence != "" && rt.NextID == nil }), nil
+}
+
+func (s *memoryReadingTargetStore) CreateNext(previous dto.ReadingTarget, readingTargets []*dto.ReadingTarget) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.targets[previous.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if stored.NextID != nil {
+		return ErrConflict
+	}
+	for _, readingTarget := range readingTargets {
+		previousID := previous.ID
+		readingTarget.PreviousID = &previousID
+		s.createWithCalendarSync(readingTarget)
+	}
+	if len(readingTargets) > 0 {
+		nextID := readingTargets[0].ID
+		stored.NextID = &nextID
+		s.targets[previous.ID] = stored
+	}
+	return nil
+}
+
+type memoryCalendarOutboxStore struct {
+	mu          sync.Mutex
+	nextID      int
+	entries     map[int]dto.CalendarOutbox
+	lockedUntil map[int]time.Time
+	targets     *memoryReadingTargetStore
+}
+
+func (s *memoryCalendarOutboxStore) enqueue(readingTarget dto.ReadingTarget, operation string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	now := time.Now()
+	s.entries[s.nextID] = dto.CalendarOutbox{
+		ID:               s.nextID,
+		TargetID:         readingTarget.ID,
+		UserID:           readingTarget.UserID,
+		Operation:        operation,
+		GoogleCalendarID: readingTarget.GoogleCalendarID,
+		Status:           dto.CalendarSyncPending,
+		NextAttemptAt:    now,
+		CreatedAt:        now,
+	}
+}
+
+func (s *memoryCalendarOutboxStore) Claim(now time.Time, lease time.Duration, limit int) ([]dto.CalendarOutbox, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pending []dto.CalendarOutbox
+	for _, entry := range s.entries {
+		if entry.Status == dto.CalendarSyncPending {
+			pending = append(pending, entry)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].ID < pending[j].ID })
+
+	var due []dto.CalendarOutbox
+	blocked := make(map[int]bool)
+	for _, entry := range pending {
+		if len(due) == limit {
+			break
+		}
+		if !blocked[entry.TargetID] && !entry.NextAttemptAt.After(now) && !s.lockedUntil[entry.ID].After(now) {
+			s.lockedUntil[entry.ID] = now.Add(lease)
+			due = append(due, entry)
+		}
+		blocked[entry.TargetID] = true
+	}
+	return due, nil
+}
+
+func (s *memoryCalendarOutboxStore) Update(entry dto.CalendarOutbox) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[entry.ID]; !ok {
+		return ErrNotFound
+	}
+	s.entries[entry.ID] = entry
+	delete(s.lockedUntil, entry.ID)
+	return nil
+}
+
+func (s *memoryCalendarOutboxStore) Complete(entry dto.CalendarOutbox, googleCalendarID string, calendarSync *dto.CalendarSync) error {
+	if err := s.Update(entry); err != nil {
+		return err
+	}
+	if calendarSync == nil {
+		return nil
+	}
+	return s.targets.UpdateCalendarSync(entry.TargetID, googleCalendarID, *calendarSync)
+}
+
+type memoryReadingProgressStore struct {
+	mu         sync.RWMutex
+	nextID     int
+	progresses map[int]dto.ReadingProgress
+}
+
+func (s *memoryReadingProgressStore) filter(match func(dto.ReadingProgress) bool) []dto.ReadingProgress {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var readingProgresses []dto.ReadingProgress
+	for _, readingProgress := range s.progresses {
+		if match(readingProgress) {
+			readingProgresses = append(readingProgresses, readingProgress)
+		}
+	}
+	sort.Slice(readingProgresses, func(i, j int) bool {
+		if readingProgresses[i].ReadAt.Equal(readingProgresses[j].ReadAt) {
+			return readingProgresses[i].ID < readingProgresses[j].ID
+		}
+		return readingProgresses[i].ReadAt.Before(readingProgresses[j].ReadAt)
+	})
+	return readingProgresses
+}
+
+func (s *memoryReadingProgressStore) GetAll() ([]dto.ReadingProgress, error) {
+	return s.filter(func(dto.ReadingProgress) bool { return true }), nil
+}
+
+func (s *memoryReadingProgressStore) GetByID(id int) (dto.ReadingProgress, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	readingProgress, ok := s.progresses[id]
+	if !ok {
+		return dto.ReadingProgress{}, ErrNotFound
+	}
+	return readingProgress, nil
+}
+
+func (s *memoryReadingProgressStore) GetByUserID(userID int) ([]dto.ReadingProgress, error) {
+	return s.filter(func(rp dto.ReadingProgress) bool { return rp.UserID == userID }), nil
+}
+
+func (s *memoryReadingProgressStore) GetByUserIDTargetID(userID, targetID int) ([]dto.ReadingProgress, error) {
+	readingProgresses := s.filter(func(rp dto.ReadingProgress) bool {
+		return rp.UserID == userID && rp.TargetID == targetID
+	})
+	for i, j := 0, len(readingProgresses)-1; i < j; i, j = i+1, j-1 {
+		readingProgresses[i], readingProgresses[j] = readingProgresses[j], readingProgresses[i]
+	}
+	return readingProgresses, nil
+}
+
+func (s *memoryReadingProgressStore) GetByTargetIDsAndTimeRange(targetIDs []int, startTime, endTime time.Time) ([]dto.ReadingProgress, error) {
+	if len(targetIDs) == 0 {
+		return nil, errors.New("empty list of target IDs")
+	}
+
+	ids := make(map[int]bool, len(targetIDs))
+	for _, id := range targetIDs {
+		ids[id] = true
+	}
+	return s.filter(func(rp dto.ReadingProgress) bool {
+		return ids[rp.TargetID] && !rp.ReadAt.Before(startTime) && !rp.ReadAt.After(endTime)
+	}), nil
+}
+
+func (s *memoryReadingProgressStore) Create(readingProgress *dto.ReadingProgress) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if readingProgress.Kind == "" {
+		readingProgress.Kind = dto.ProgressKindRead
+	}
+	if readingProgress.PageShare == 0 {
+		readingProgress.PageShare = 1
+	}
+	for _, stored := range s.progresses {
+		if stored.UserID == readingProgress.UserID && stored.TargetID == readingProgress.TargetID && stored.CurrentPage == readingProgress.CurrentPage &&
+			uniquePage(stored) && uniquePage(*readingProgress) {
+			return ErrConflict
+		}
+	}
+
+	s.nextID++
+	readingProgress.ID = s.nextID
+	if readingProgress.TimeStamp.IsZero() {
+		readingProgress.TimeStamp = time.Now()
+	}
+	if readingProgress.ReadAt.IsZero() {
+		readingProgress.ReadAt = readingProgress.TimeStamp
+	}
+	s.progresses[readingProgress.ID] = *readingProgress
+	return nil
+}
+
+// uniquePage reports whether the entry is covered by the unique page index,
+// only whole page first readings are.
+func uniquePage(readingProgress dto.ReadingProgress) bool {
+	return readingProgress.Kind == dto.ProgressKindRead && readingProgress.StartVerse == ""
+}
+
+func (s *memoryReadingProgressStore) CreateBatch(readingProgresses []*dto.ReadingProgress) error {
+	for _, readingProgress := range readingProgresses {
+		err := s.Create(readingProgress)
+		if errors.Is(err, ErrConflict) {
+			readingProgress.ID = 0
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *memoryReadingProgressStore) Update(readingProgress dto.ReadingProgress) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.progresses[readingProgress.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	stored.CurrentPage = readingProgress.CurrentPage
+	stored.StartVerse = readingProgress.StartVerse
+	stored.EndVerse = readingProgress.EndVerse
+	stored.PageShare = readingProgress.PageShare
+	for _, other := range s.progresses {
+		if other.ID != stored.ID && other.UserID == stored.UserID && other.TargetID == stored.TargetID && other.CurrentPage == stored.CurrentPage &&
+			uniquePage(other) && uniquePage(stored) {
+			return ErrConflict
+		}
+	}
+	s.progresses[readingProgress.ID] = stored
+	return nil
+}
+
+func (s *memoryReadingProgressStore) Delete(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.progresses, id)
+	return nil
+}
+
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]dto.IdempotencyRecord
+}
+
+func (s *memoryIdempotencyStore) Reserve(record *dto.IdempotencyRecord, expiredBefore time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.records[record.Key]
+	if ok && !stored.CreatedAt.Before(expiredBefore) {
+		*record = stored
+		return false, nil
+	}
+
+	record.StatusCode = 0
+	record.Response = nil
+	record.CreatedAt = time.Now()
+	s.records[record.Key] = *record
+	return true, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(record dto.IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.records[record.Key]
+	if !ok {
+		return ErrNotFound
+	}
+	stored.StatusCode = record.StatusCode
+	stored.Response = record.Response
+	s.records[record.Key] = stored
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+type memoryReadingStatsStore struct {
+	progress *memoryReadingProgressStore
+}
+
+// truncatePeriod returns the first day of the day, week (from Monday) or
+// month holding t, like date_trunc.
+func truncatePeriod(t time.Time, period string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch period {
+	case dto.StatsPeriodWeek:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case dto.StatsPeriodMonth:
+		return day.AddDate(0, 0, 1-day.Day())
+	}
+	return day
+}
+
+func nextPeriod(t time.Time, period string) time.Time {
+	switch period {
+	case dto.StatsPeriodWeek:
+		return t.AddDate(0, 0, 7)
+	case dto.StatsPeriodMonth:
+		return t.AddDate(0, 1, 0)
+	}
+	return t.AddDate(0, 0, 1)
+}
+
+// roundPages keeps page sums to two decimals like the SQL aggregates.
+func roundPages(pages float64) float64 {
+	return math.Round(pages*100) / 100
+}
+
+func (s *memoryReadingStatsStore) localCounts(userID int, period string, timezone string) (map[time.Time]dto.PeriodPages, error) {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+	readingProgresses, _ := s.progress.GetByUserID(userID)
+	counts := make(map[time.Time]dto.PeriodPages)
+	for _, readingProgress := range readingProgresses {
+		local := readingProgress.ReadAt.In(location)
+		date := truncatePeriod(time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC), period)
+		count := counts[date]
+		count.Pages += readingProgress.PageShare
+		if readingProgress.Kind == dto.ProgressKindReview {
+			count.ReviewPages += readingProgress.PageShare
+		}
+		counts[date] = count
+	}
+	for date, count := range counts {
+		count.Pages, count.ReviewPages = roundPages(count.Pages), roundPages(count.ReviewPages)
+		counts[date] = count
+	}
+	return counts, nil
+}
+
+func (s *memoryReadingStatsStore) GetPagesPerPeriod(userID int, period string, timezone string, from, to time.Time) ([]dto.PeriodPages, error) {
+	counts, err := s.localCounts(userID, period, timezone)
+	if err != nil {
+		return nil, err
+	}
+	periodPages := make([]dto.PeriodPages, 0)
+	for date := truncatePeriod(from, period); !date.After(truncatePeriod(to, period)); date = nextPeriod(date, period) {
+		count := counts[date]
+		count.Date = date.Format("2006-01-02")
+		periodPages = append(periodPages, count)
+	}
+	return periodPages, nil
+}
+
+func (s *memoryReadingStatsStore) GetSummary(userID int, timezone string) (dto.ReadingSummary, error) {
+	counts, err := s.localCounts(userID, dto.StatsPeriodDay, timezone)
+	if err != nil {
+		return dto.ReadingSummary{}, err
+	}
+	var summary dto.ReadingSummary
+	for day, count := range counts {
+		date, pages := day.Format("2006-01-02"), count.Pages
+		summary.TotalPages = roundPages(summary.TotalPages + pages)
+		summary.ReviewPages = roundPages(summary.ReviewPages + count.ReviewPages)
+		summary.ActiveDays++
+		if summary.FirstDay == "" || date < summary.FirstDay {
+			summary.FirstDay = date
+		}
+		if pages > summary.BestDayPages || (pages == summary.BestDayPages && date > summary.BestDay) {
+			summary.BestDay = date
+			summary.BestDayPages = pages
+			summary.BestDayReviewPages = count.ReviewPages
+		}
+	}
+	return summary, nil
+}
+
+func (s *memoryReadingStatsStore) GetTargetPages(userID int) ([]dto.TargetPages, error) {
+	readingProgresses, _ := s.progress.GetByUserID(userID)
+	counts := make(map[int]float64)
+	for _, readingProgress := range readingProgresses {
+		if readingProgress.Kind == dto.ProgressKindRead {
+			counts[readingProgress.TargetID] += readingProgress.PageShare
+		}
+	}
+	var targetPages []dto.TargetPages
+	for targetID, pages := range counts {
+		targetPages = append(targetPages, dto.TargetPages{TargetID: targetID, PagesRead: roundPages(pages)})
+	}
+	sort.Slice(targetPages, func(i, j int) bool { return targetPages[i].TargetID < targetPages[j].TargetID })
+	return targetPages, nil
+}
+
+type memoryHifzStore struct {
+	mu           sync.RWMutex
+	nextID       int
+	nextReviewID int
+	// pages is keyed by user ID and page
+	pages   map[[2]int]dto.HifzPage
+	reviews []dto.HifzReview
+}
+
+func (s *memoryHifzStore) filter(match func(dto.HifzPage) bool) []dto.HifzPage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hifzPages := make([]dto.HifzPage, 0)
+	for _, hifzPage := range s.pages {
+		if match(hifzPage) {
+			hifzPages = append(hifzPages, hifzPage)
+		}
+	}
+	sort.Slice(hifzPages, func(i, j int) bool { return hifzPages[i].Page < hifzPages[j].Page })
+	return hifzPages
+}
+
+func (s *memoryHifzStore) GetByUserID(userID int) ([]dto.HifzPage, error) {
+	return s.filter(func(hp dto.HifzPage) bool { return hp.UserID == userID }), nil
+}
+
+func (s *memoryHifzStore) GetByUserIDPage(userID, page int) (dto.HifzPage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hifzPage, ok := s.pages[[2]int{userID, page}]
+	if !ok {
+		return dto.HifzPage{}, ErrNotFound
+	}
+	return hifzPage, nil
+}
+
+func (s *memoryHifzStore) GetDue(userID int, date string) ([]dto.HifzPage, error) {
+	hifzPages := s.filter(func(hp dto.HifzPage) bool { return hp.UserID == userID && hp.DueDate <= date })
+	sort.SliceStable(hifzPages, func(i, j int) bool { return hifzPages[i].DueDate < hifzPages[j].DueDate })
+	return hifzPages, nil
+}
+
+func (s *memoryHifzStore) Save(hifzPage *dto.HifzPage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := [2]int{hifzPage.UserID, hifzPage.Page}
+	if stored, ok := s.pages[key]; ok {
+		hifzPage.ID = stored.ID
+	} else {
+		s.nextID++
+		hifzPage.ID = s.nextID
+	}
+	s.pages[key] = *hifzPage
+	return nil
+}
+
+func (s *memoryHifzStore) SaveReview(hifzPage dto.HifzPage, review *dto.HifzReview) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := [2]int{hifzPage.UserID, hifzPage.Page}
+	stored, ok := s.pages[key]
+	if !ok {
+		return ErrNotFound
+	}
+	stored.Repetitions = hifzPage.Repetitions
+	stored.IntervalDays = hifzPage.IntervalDays
+	stored.EaseFactor = hifzPage.EaseFactor
+	stored.DueDate = hifzPage.DueDate
+	stored.LastReviewedAt = hifzPage.LastReviewedAt
+	s.pages[key] = stored
+
+	s.nextReviewID++
+	review.ID = s.nextReviewID
+	s.reviews = append(s.reviews, *review)
+	return nil
+}
+
+func (s *memoryHifzStore) GetReviews(userID, page int) ([]dto.HifzReview, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	reviews := make([]dto.HifzReview, 0)
+	for i := len(s.reviews) - 1; i >= 0; i-- {
+		if s.reviews[i].UserID == userID && s.reviews[i].Page == page {
+			reviews = append(reviews, s.reviews[i])
+		}
+	}
+	return reviews, nil
+}
+
+func (s *memoryHifzStore) Delete(userID, page int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.pages, [2]int{userID, page})
+	reviews := s.reviews[:0]
+	for _, review := range s.reviews {
+		if review.UserID != userID || review.Page != page {
+			reviews = append(reviews, review)
+		}
+	}
+	s.reviews = reviews
+	return nil
+}
+
+type memoryBookmarkStore struct {
+	mu        sync.RWMutex
+	bookmarks map[int]dto.BookmarkPin
+}
+
+func (s *memoryBookmarkStore) Get(userID int) (dto.BookmarkPin, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	bookmark, ok := s.bookmarks[userID]
+	if !ok {
+		return dto.BookmarkPin{}, ErrNotFound
+	}
+	return bookmark, nil
+}
+
+func (s *memoryBookmarkStore) Save(bookmark *dto.BookmarkPin) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.bookmarks[bookmark.UserID] = *bookmark
+	return nil
+}
+
+func (s *memoryBookmarkStore) Delete(userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.bookmarks, userID)
+	return nil
+}
+
+type memoryReflectionStore struct {
+	mu          sync.RWMutex
+	nextID      int
+	reflections map[int]dto.Reflection
+	progress    *memoryReadingProgressStore
+}
+
+// resolve clears the progress ID of a deleted entry, like ON DELETE SET NULL.
+func (s *memoryReflectionStore) resolve(reflection dto.Reflection) dto.Reflection {
+	if _, err := s.progress.GetByID(reflection.ProgressID); err != nil {
+		reflection.ProgressID = 0
+	}
+	return reflection
+}
+
+func (s *memoryReflectionStore) filter(match func(dto.Reflection) bool) []dto.Reflection {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	reflections := make([]dto.Reflection, 0)
+	for _, reflection := range s.reflections {
+		reflection = s.resolve(reflection)
+		if match(reflection) {
+			reflections = append(reflections, reflection)
+		}
+	}
+	sort.Slice(reflections, func(i, j int) bool {
+		if reflections[i].CreatedAt.Equal(reflections[j].CreatedAt) {
+			return reflections[i].ID > reflections[j].ID
+		}
+		return reflections[i].CreatedAt.After(reflections[j].CreatedAt)
+	})
+	return reflections
+}
+
+func (s *memoryReflectionStore) GetByID(id int) (dto.Reflection, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	reflection, ok := s.reflections[id]
+	if !ok {
+		return dto.Reflection{}, ErrNotFound
+	}
+	return s.resolve(reflection), nil
+}
+
+// Search matches a note holding every word of the query, or a tag equal to
+// it, as a stand-in for the full-text search.
+func (s *memoryReflectionStore) Search(userID int, filter dto.ReflectionFilter) ([]dto.Reflection, error) {
+	words := strings.Fields(strings.ToLower(filter.Query))
+	return s.filter(func(reflection dto.Reflection) bool {
+		if reflection.UserID != userID || (filter.ProgressID != 0 && reflection.ProgressID != filter.ProgressID) {
+			return false
+		}
+		if filter.Tag != "" && !containsString(reflection.Tags, filter.Tag) {
+			return false
+		}
+		if len(words) == 0 || containsString(reflection.Tags, strings.ToLower(filter.Query)) {
+			return true
+		}
+		note := strings.ToLower(reflection.Note)
+		for _, word := range words {
+			if !strings.Contains(note, word) {
+				return false
+			}
+		}
+		return true
+	}), nil
+}
+
+func (s *memoryReflectionStore) GetPublic(verseKey string, limit int) ([]dto.Reflection, error) {
+	reflections := s.filter(func(reflection dto.Reflection) bool {
+		return reflection.IsPublic && (verseKey == "" || reflection.VerseKey == verseKey)
+	})
+	if len(reflections) > limit {
+		reflections = reflections[:limit]
+	}
+	return reflections, nil
+}
+
+func (s *memoryReflectionStore) Create(reflection *dto.Reflection) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	reflection.ID = s.nextID
+	reflection.CreatedAt = time.Now()
+	reflection.UpdatedAt = reflection.CreatedAt
+	s.reflections[reflection.ID] = *reflection
+	return nil
+}
+
+func (s *memoryReflectionStore) Update(reflection *dto.Reflection) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.reflections[reflection.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	stored.VerseKey = reflection.VerseKey
+	stored.Note = reflection.Note
+	stored.Tags = reflection.Tags
+	stored.IsPublic = reflection.IsPublic
+	stored.UpdatedAt = time.Now()
+	s.reflections[reflection.ID] = stored
+	reflection.UpdatedAt = stored.UpdatedAt
+	return nil
+}
+
+func (s *memoryReflectionStore) Delete(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.reflections, id)
+	return nil
+}
+
+type memoryKhatamStore struct {
+	mu      sync.RWMutex
+	nextID  int
+	khatams map[int]dto.Khatam
+}
+
+func (s *memoryKhatamStore) GetByUserID(userID int) ([]dto.Khatam, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	khatams := make([]dto.Khatam, 0)
+	for _, khatam := range s.khatams {
+		if khatam.UserID == userID {
+			khatams = append(khatams, khatam)
+		}
+	}
+	sort.Slice(khatams, func(i, j int) bool {
+		if khatams[i].CompletedAt.Equal(khatams[j].CompletedAt) {
+			return khatams[i].ID > khatams[j].ID
+		}
+		return khatams[i].CompletedAt.After(khatams[j].CompletedAt)
+	})
+	return khatams, nil
+}
+
+func (s *memoryKhatamStore) Create(khatam *dto.Khatam) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.khatams {
+		if existing.TargetID == khatam.TargetID {
+			return ErrConflict
+		}
+	}
+	s.nextID++
+	khatam.ID = s.nextID
+	s.khatams[khatam.ID] = *khatam
+	return nil
+}
+
+type memoryGroupStore struct {
+	mu               sync.RWMutex
+	nextID           int
+	nextTargetID     int
+	nextAssignmentID int
+	groups           map[int]dto.Group
+	groupTargets     map[int]dto.GroupTarget
+	users            *memoryUserStore
+	readingTargets   *memoryReadingTargetStore
+}
+
+// withUsers copies a group and fills the usernames of its members.
+func (s *memoryGroupStore) withUsers(group dto.Group) dto.Group {
+	members := make([]dto.GroupMember, 0, len(group.Members))
+	for _, member := range group.Members {
+		if user, err := s.users.GetByID(member.UserID); err == nil {
+			member.Username = user.Username
+			member.DisplayName = user.DisplayName
+		}
+		members = append(members, member)
+	}
+	group.Members = members
+	return group
+}
+
+// withAssignments copies a group target and fills the usernames of its
+// assignments, ordered by page.
+func (s *memoryGroupStore) withAssignments(groupTarget dto.GroupTarget) dto.GroupTarget {
+	assignments := make([]dto.GroupAssignment, 0, len(groupTarget.Assignments))
+	for _, assignment := range groupTarget.Assignments {
+		if user, err := s.users.GetByID(assignment.UserID); err == nil {
+			assignment.Username = user.Username
+		}
+		assignments = append(assignments, assignment)
+	}
+	sort.SliceStable(assignments, func(i, j int) bool { return assignments[i].StartPage < assignments[j].StartPage })
+	groupTarget.Assignments = assignments
+	return groupTarget
+}
+
+func (s *memoryGroupStore) GetByID(id int) (dto.Group, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	group, ok := s.groups[id]
+	if !ok {
+		return dto.Group{}, ErrNotFound
+	}
+	return s.withUsers(group), nil
+}
+
+func (s *memoryGroupStore) GetByUserID(userID int) ([]dto.Group, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	groups := make([]dto.Group, 0)
+	for _, group := range s.groups {
+		for _, member := range group.Members {
+			if member.UserID == userID {
+				group.Members = nil
+				groups = append(groups, group)
+				break
+			}
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].ID < groups[j].ID })
+	return groups, nil
+}
+
+func (s *memoryGroupStore) Create(group *dto.Group) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	group.ID = s.nextID
+	group.CreatedAt = time.Now()
+	group.Members = []dto.GroupMember{{UserID: group.OwnerID, JoinedAt: group.CreatedAt}}
+	s.groups[group.ID] = *group
+	return nil
+}
+
+func (s *memoryGroupStore) Delete(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.groups, id)
+	for targetID, groupTarget := range s.groupTargets {
+		if groupTarget.GroupID == id {
+			delete(s.groupTargets, targetID)
+		}
+	}
+	return nil
+}
+
+func (s *memoryGroupStore) AddMember(groupID, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	group, ok := s.groups[groupID]
+	if !ok {
+		return ErrNotFound
+	}
+	for _, member := range group.Members {
+		if member.UserID == userID {
+			return ErrConflict
+		}
+	}
+	group.Members = append(append([]dto.GroupMember(nil), group.Members...), dto.GroupMember{UserID: userID, JoinedAt: time.Now()})
+	s.groups[groupID] = group
+	return nil
+}
+
+func (s *memoryGroupStore) RemoveMember(groupID, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	group, ok := s.groups[groupID]
+	if !ok {
+		return nil
+	}
+	members := make([]dto.GroupMember, 0, len(group.Members))
+	for _, member := range group.Members {
+		if member.UserID != userID {
+			members = append(members, member)
+		}
+	}
+	group.Members = members
+	s.groups[groupID] = group
+	return nil
+}
+
+func (s *memoryGroupStore) GetTargets(groupID int) ([]dto.GroupTarget, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	groupTargets := make([]dto.GroupTarget, 0)
+	for _, groupTarget := range s.groupTargets {
+		if groupTarget.GroupID == groupID {
+			groupTargets = append(groupTargets, s.withAssignments(groupTarget))
+		}
+	}
+	sort.Slice(groupTargets, func(i, j int) bool { return groupTargets[i].ID < groupTargets[j].ID })
+	return groupTargets, nil
+}
+
+func (s *memoryGroupStore) GetTarget(id int) (dto.GroupTarget, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	groupTarget, ok := s.groupTargets[id]
+	if !ok {
+		return dto.GroupTarget{}, ErrNotFound
+	}
+	return s.withAssignments(groupTarget), nil
+}
+
+func (s *memoryGroupStore) CreateTarget(groupTarget *dto.GroupTarget) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextTargetID++
+	groupTarget.ID = s.nextTargetID
+	groupTarget.CreatedAt = time.Now()
+	for i := range groupTarget.Assignments {
+		if err := s.createAssignment(*groupTarget, &groupTarget.Assignments[i]); err != nil {
+			return err
+		}
+	}
+	stored := *groupTarget
+	stored.Assignments = append([]dto.GroupAssignment(nil), groupTarget.Assignments...)
+	s.groupTargets[groupTarget.ID] = stored
+	return nil
+}
+
+func (s *memoryGroupStore) Reassign(groupTarget dto.GroupTarget, assignmentID int, assignments []*dto.GroupAssignment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.groupTargets[groupTarget.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	stored.Assignments = append([]dto.GroupAssignment(nil), stored.Assignments...)
+	targetID := 0
+	for i := range stored.Assignments {
+		if stored.Assignments[i].ID == assignmentID && stored.Assignments[i].Status == dto.GroupAssignmentActive {
+			stored.Assignments[i].Status = dto.GroupAssignmentReassigned
+			targetID = stored.Assignments[i].TargetID
+		}
+	}
+	if targetID == 0 {
+		return ErrNotFound
+	}
+	s.readingTargets.markReassigned(targetID)
+	for _, assignment := range assignments {
+		if err := s.createAssignment(stored, assignment); err != nil {
+			return err
+		}
+		stored.Assignments = append(stored.Assignments, *assignment)
+	}
+	s.groupTargets[groupTarget.ID] = stored
+	return nil
+}
+
+func (s *memoryGroupStore) createAssignment(groupTarget dto.GroupTarget, assignment *dto.GroupAssignment) error {
+	readingTarget := assignmentTarget(groupTarget, *assignment)
+	if err := s.readingTargets.CreateWithCalendarSync(&readingTarget); err != nil {
+		return err
+	}
+	s.nextAssignmentID++
+	assignment.ID = s.nextAssignmentID
+	assignment.GroupTargetID = groupTarget.ID
+	assignment.TargetID = readingTarget.ID
+	assignment.Status = dto.GroupAssignmentActive
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+type memoryTargetTemplateStore struct {
+	mu        sync.RWMutex
+	nextID    int
+	templates map[int]dto.TargetTemplate
+}
+
+func (s *memoryTargetTemplateStore) GetAll() ([]dto.TargetTemplate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	templates := make([]dto.TargetTemplate, 0, len(s.templates))
+	for _, template := range s.templates {
+		templates = append(templates, template)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].ID < templates[j].ID })
+	return templates, nil
+}
+
+func (s *memoryTargetTemplateStore) GetByID(id int) (dto.TargetTemplate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	template, ok := s.templates[id]
+	if !ok {
+		return dto.TargetTemplate{}, ErrNotFound
+	}
+	return template, nil
+}
+
+func (s *memoryTargetTemplateStore) Create(template *dto.TargetTemplate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	template.ID = s.nextID
+	template.CreatedAt = time.Now()
+	s.templates[template.ID] = *template
+	return nil
+}
+
+func (s *memoryTargetTemplateStore) Delete(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.templates[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.templates, id)
+	return nil
+}
@@ -0,0 +1,1211 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/dto"
+	"github.com/daffashafwan/tadarus-yuk/internal/helpers"
+	"github.com/lib/pq"
+)
+
+const (
+	userColumns            = "id, username, email, password, google_token, display_name, timezone, streak_grace_days"
+	adminColumns           = "id, username, email, password"
+	readingTargetColumns   = "target_id, user_id, start_date, end_date, target_pages_per_interval, name, start_page, end_page, google_calendar_id, is_public, calendar_sync_status, calendar_sync_error, calendar_synced_at, status, paused_at, paused_days, recurrence, carry_over, previous_id, next_id, scope"
+	calendarOutboxColumns  = "id, target_id, user_id, operation, google_calendar_id, status, attempts, next_attempt_at, last_error, created_at"
+	readingProgressColumns = "progress_id, user_id, target_id, current_page, last_update_timestamp, read_at, kind, start_verse, end_verse, page_share"
+	hifzPageColumns        = "hifz_id, user_id, page, first_verse, last_verse, repetitions, interval_days, ease_factor, to_char(due_date, 'YYYY-MM-DD'), memorized_at, last_reviewed_at"
+	reflectionColumns      = "reflection_id, user_id, progress_id, page, verse_key, note, tags, is_public, created_at, updated_at"
+	khatamColumns          = "khatam_id, user_id, target_id, target_name, start_page, end_page, started_at, completed_at, duration_days"
+	groupTargetColumns     = "group_target_id, group_id, name, to_char(start_date, 'YYYY-MM-DD'), to_char(end_date, 'YYYY-MM-DD'), start_page, end_page, created_at"
+	groupAssignmentColumns = "a.assignment_id, a.group_target_id, a.user_id, u.username, a.target_id, a.start_page, a.end_page, a.status"
+	targetTemplateColumns  = "template_id, name, description, start_page, end_page, pages, duration_days, weekday, recurrence, created_at"
+	hifzReviewColumns      = "review_id, user_id, page, quality, interval_days, ease_factor, to_char(due_date, 'YYYY-MM-DD'), reviewed_at"
+)
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// NewPostgresStore returns a Store backed by the given PostgreSQL connection.
+func NewPostgresStore(conn *sql.DB) Store {
+	return Store{
+		Users:           &postgresUserStore{db: conn},
+		ReadingTargets:  &postgresReadingTargetStore{db: conn},
+		ReadingProgress: &postgresReadingProgressStore{db: conn},
+		CalendarOutbox:  &postgresCalendarOutboxStore{db: conn},
+		Idempotency:     &postgresIdempotencyStore{db: conn},
+		ReadingStats:    &postgresReadingStatsStore{db: conn},
+		Hifz:            &postgresHifzStore{db: conn},
+		Bookmarks:       &postgresBookmarkStore{db: conn},
+		Reflections:     &postgresReflectionStore{db: conn},
+		Khatams:         &postgresKhatamStore{db: conn},
+		Groups:          &postgresGroupStore{db: conn},
+		Templates:       &postgresTargetTemplateStore{db: conn},
+	}
+}
+
+// uniqueViolation maps a unique constraint error to ErrConflict.
+func uniqueViolation(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrConflict
+	}
+	return err
+}
+
+func notFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+type postgresUserStore struct {
+	db *sql.DB
+}
+
+func scanUser(row rowScanner) (dto.User, error) {
+	var user dto.User
+	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.GoogleToken, &user.DisplayName, &user.Timezone, &user.StreakGraceDays)
+	return user, err
+}
+
+func (s *postgresUserStore) GetAll() ([]dto.User, error) {
+	rows, err := s.db.Query("SELECT " + userColumns + " FROM users")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []dto.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+func (s *postgresUserStore) GetByID(id int) (dto.User, error) {
+	user, err := scanUser(s.db.QueryRow("SELECT "+userColumns+" FROM users WHERE id = $1", id))
+	return user, notFound(err)
+}
+
+func (s *postgresUserStore) GetByUsername(username string) (dto.User, error) {
+	user, err := scanUser(s.db.QueryRow("SELECT "+userColumns+" FROM users WHERE username = $1", username))
+	return user, notFound(err)
+}
+
+func (s *postgresUserStore) GetByEmail(email string) (dto.User, error) {
+	user, err := scanUser(s.db.QueryRow("SELECT "+userColumns+" FROM users WHERE email = $1", email))
+	return user, notFound(err)
+}
+
+func (s *postgresUserStore) Create(user *dto.User, hashedPassword string) error {
+	query := "INSERT INTO users (username, email, password, google_token, display_name) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+	return s.db.QueryRow(query, user.Username, user.Email, hashedPassword, user.GoogleToken, user.Email).Scan(&user.ID)
+}
+
+func (s *postgresUserStore) Update(user dto.User) error {
+	query := "UPDATE users SET username = $1, email = $2, google_token = $3, display_name = $4, timezone = $5, streak_grace_days = $6 WHERE id = $7"
+	_, err := s.db.Exec(query, user.Username, user.Email, user.GoogleToken, user.DisplayName, user.Timezone, user.StreakGraceDays, user.ID)
+	return err
+}
+
+func (s *postgresUserStore) Delete(id int) error {
+	_, err := s.db.Exec("DELETE FROM users WHERE id = $1", id)
+	return err
+}
+
+func (s *postgresUserStore) GetAdminByUsername(username string) (dto.Admin, error) {
+	var admin dto.Admin
+	row := s.db.QueryRow("SELECT "+adminColumns+" FROM admin WHERE username = $1", username)
+	err := row.Scan(&admin.ID, &admin.Username, &admin.Email, &admin.Password)
+	return admin, notFound(err)
+}
+
+type postgresReadingTargetStore struct {
+	db *sql.DB
+}
+
+func scanReadingTarget(row rowScanner) (dto.ReadingTarget, error) {
+	var readingTarget dto.ReadingTarget
+	var syncedAt, pausedAt sql.NullTime
+	var previousID, nextID sql.NullInt64
+	var scope []byte
+	err := row.Scan(&readingTarget.ID, &readingTarget.UserID, &readingTarget.StartDate, &readingTarget.EndDate, &readingTarget.Pages, &readingTarget.Name, &readingTarget.StartPage, &readingTarget.EndPage, &readingTarget.GoogleCalendarID, &readingTarget.IsPublic, &readingTarget.CalendarSync.Status, &readingTarget.CalendarSync.Error, &syncedAt, &readingTarget.Status, &pausedAt, &readingTarget.PausedDays, &readingTarget.Recurrence, &readingTarget.CarryOver, &previousID, &nextID, &scope)
+	if err != nil {
+		return readingTarget, err
+	}
+	if syncedAt.Valid {
+		readingTarget.CalendarSync.SyncedAt = &syncedAt.Time
+	}
+	if pausedAt.Valid {
+		readingTarget.PausedAt = &pausedAt.Time
+	}
+	if previousID.Valid {
+		id := int(previousID.Int64)
+		readingTarget.PreviousID = &id
+	}
+	if nextID.Valid {
+		id := int(nextID.Int64)
+		readingTarget.NextID = &id
+	}
+	if len(scope) > 0 {
+		readingTarget.Scope = &dto.TargetScope{}
+		err = json.Unmarshal(scope, readingTarget.Scope)
+	}
+	return readingTarget, err
+}
+
+// scopeValue encodes the scope of a target for the scope column, NULL when
+// the target has none.
+func scopeValue(scope *dto.TargetScope) (interface{}, error) {
+	if scope == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(scope)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (s *postgresReadingTargetStore) query(query string, args ...interface{}) ([]dto.ReadingTarget, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var readingTargets []dto.ReadingTarget
+	for rows.Next() {
+		readingTarget, err := scanReadingTarget(rows)
+		if err != nil {
+			return nil, err
+		}
+		readingTargets = append(readingTargets, readingTarget)
+	}
+	return readingTargets, rows.Err()
+}
+
+func (s *postgresReadingTargetStore) GetAll() ([]dto.ReadingTarget, error) {
+	return s.query("SELECT " + readingTargetColumns + " FROM reading_target")
+}
+
+func (s *postgresReadingTargetStore) GetByID(id int) (dto.ReadingTarget, error) {
+	readingTarget, err := scanReadingTarget(s.db.QueryRow("SELECT "+readingTargetColumns+" FROM reading_target WHERE target_id = $1", id))
+	return readingTarget, notFound(err)
+}
+
+func (s *postgresReadingTargetStore) GetByUserID(userID int) ([]dto.ReadingTarget, error) {
+	return s.query("SELECT "+readingTargetColumns+" FROM reading_target WHERE user_id = $1", userID)
+}
+
+func (s *postgresReadingTargetStore) GetPublic() ([]dto.ReadingTarget, error) {
+	return s.query("SELECT "+readingTargetColumns+" FROM reading_target WHERE is_public = $1", true)
+}
+
+func (s *postgresReadingTargetStore) Create(readingTarget *dto.ReadingTarget) error {
+	scope, err := scopeValue(readingTarget.Scope)
+	if err != nil {
+		return err
+	}
+	query := "INSERT INTO reading_target (user_id, name, start_date, end_date, start_page, end_page, target_pages_per_interval, google_calendar_id, is_public, status, recurrence, carry_over, previous_id, scope) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, COALESCE(NULLIF($10, ''), 'active'), $11, $12, $13, $14) RETURNING target_id"
+	return s.db.QueryRow(query, readingTarget.UserID, readingTarget.Name, readingTarget.StartDate, readingTarget.EndDate, readingTarget.StartPage, readingTarget.EndPage, readingTarget.Pages, readingTarget.GoogleCalendarID, readingTarget.IsPublic, readingTarget.Status, readingTarget.Recurrence, readingTarget.CarryOver, readingTarget.PreviousID, scope).Scan(&readingTarget.ID)
+}
+
+// Update leaves google_calendar_id alone, it is owned by the calendar sync worker.
+func (s *postgresReadingTargetStore) Update(readingTarget dto.ReadingTarget) error {
+	return updateReadingTarget(s.db, readingTarget)
+}
+
+func (s *postgresReadingTargetStore) Delete(id int) error {
+	_, err := s.db.Exec("DELETE FROM reading_target WHERE target_id = $1", id)
+	return err
+}
+
+func (s *postgresReadingTargetStore) CreateWithCalendarSync(readingTarget *dto.ReadingTarget) error {
+	return withTx(s.db, func(tx *sql.Tx) error {
+		return createReadingTargetWithCalendarSync(tx, readingTarget)
+	})
+}
+
+func (s *postgresReadingTargetStore) UpdateWithCalendarSync(readingTarget dto.ReadingTarget) error {
+	return withTx(s.db, func(tx *sql.Tx) error {
+		if err := updateReadingTarget(tx, readingTarget); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("UPDATE reading_target SET calendar_sync_status = $1 WHERE target_id = $2", dto.CalendarSyncPending, readingTarget.ID); err != nil {
+			return err
+		}
+		return enqueueCalendarOutbox(tx, readingTarget, "EDIT")
+	})
+}
+
+func (s *postgresReadingTargetStore) DeleteWithCalendarSync(readingTarget dto.ReadingTarget) error {
+	return withTx(s.db, func(tx *sql.Tx) error {
+		// Read the calendar ID inside the transaction so a concurrent sync is not missed.
+		err := tx.QueryRow("SELECT google_calendar_id FROM reading_target WHERE target_id = $1 FOR UPDATE", readingTarget.ID).Scan(&readingTarget.GoogleCalendarID)
+		if err != nil {
+			return notFound(err)
+		}
+		if _, err := tx.Exec("DELETE FROM reading_target WHERE target_id = $1", readingTarget.ID); err != nil {
+			return err
+		}
+		return enqueueCalendarOutbox(tx, readingTarget, "DELETE")
+	})
+}
+
+func (s *postgresReadingTargetStore) UpdateCalendarSync(targetID int, googleCalendarID string, calendarSync dto.CalendarSync) error {
+	return updateCalendarSync(s.db, targetID, googleCalendarID, calendarSync)
+}
+
+func updateCalendarSync(conn execer, targetID int, googleCalendarID string, calendarSync dto.CalendarSync) error {
+	query := "UPDATE reading_target SET google_calendar_id = COALESCE(NULLIF($1, ''), google_calendar_id), calendar_sync_status = $2, calendar_sync_error = $3, calendar_synced_at = COALESCE($4, calendar_synced_at) WHERE target_id = $5"
+	_, err := conn.Exec(query, googleCalendarID, calendarSync.Status, calendarSync.Error, calendarSync.SyncedAt, targetID)
+	return err
+}
+
+func (s *postgresReadingTargetStore) GetRecurring() ([]dto.ReadingTarget, error) {
+	return s.query("SELECT " + readingTargetColumns + " FROM reading_target WHERE recurrence <> '' AND next_id IS NULL")
+}
+
+func (s *postgresReadingTargetStore) CreateNext(previous dto.ReadingTarget, readingTargets []*dto.ReadingTarget) error {
+	return withTx(s.db, func(tx *sql.Tx) error {
+		var nextID sql.NullInt64
+		err := tx.QueryRow("SELECT next_id FROM reading_target WHERE target_id = $1 FOR UPDATE", previous.ID).Scan(&nextID)
+		if err != nil {
+			return notFound(err)
+		}
+		if nextID.Valid {
+			return ErrConflict
+		}
+		for _, readingTarget := range readingTargets {
+			readingTarget.PreviousID = &previous.ID
+			if err := createReadingTargetWithCalendarSync(tx, readingTarget); err != nil {
+				return err
+			}
+		}
+		if len(readingTargets) == 0 {
+			return nil
+		}
+		_, err = tx.Exec("UPDATE reading_target SET next_id = $1 WHERE target_id = $2", readingTargets[0].ID, previous.ID)
+		return err
+	})
+}
+
+// execer is implemented by both *sql.DB and *sql.Tx.
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func updateReadingTarget(conn execer, readingTarget dto.ReadingTarget) error {
+	query := "UPDATE reading_target SET name = $1, start_date = $2, end_date = $3, start_page = $4, end_page = $5, target_pages_per_interval = $6, is_public = $7, status = COALESCE(NULLIF($8, ''), status), paused_at = $9, paused_days = $10, recurrence = $11, carry_over = $12 WHERE target_id = $13"
+	_, err := conn.Exec(query, readingTarget.Name, readingTarget.StartDate, readingTarget.EndDate, readingTarget.StartPage, readingTarget.EndPage, readingTarget.Pages, readingTarget.IsPublic, readingTarget.Status, readingTarget.PausedAt, readingTarget.PausedDays, readingTarget.Recurrence, readingTarget.CarryOver, readingTarget.ID)
+	return err
+}
+
+func createReadingTargetWithCalendarSync(tx *sql.Tx, readingTarget *dto.ReadingTarget) error {
+	scope, err := scopeValue(readingTarget.Scope)
+	if err != nil {
+		return err
+	}
+	query := "INSERT INTO reading_target (user_id, name, start_date, end_date, start_page, end_page, target_pages_per_interval, google_calendar_id, is_public, calendar_sync_status, status, recurrence, carry_over, previous_id, scope) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, COALESCE(NULLIF($11, ''), 'active'), $12, $13, $14, $15) RETURNING target_id"
+	err = tx.QueryRow(query, readingTarget.UserID, readingTarget.Name, readingTarget.StartDate, readingTarget.EndDate, readingTarget.StartPage, readingTarget.EndPage, readingTarget.Pages, readingTarget.GoogleCalendarID, readingTarget.IsPublic, dto.CalendarSyncPending, readingTarget.Status, readingTarget.Recurrence, readingTarget.CarryOver, readingTarget.PreviousID, scope).Scan(&readingTarget.ID)
+	if err != nil {
+		return err
+	}
+	readingTarget.CalendarSync = dto.CalendarSync{Status: dto.CalendarSyncPending}
+	return enqueueCalendarOutbox(tx, *readingTarget, "ADD")
+}
+
+func enqueueCalendarOutbox(tx *sql.Tx, readingTarget dto.ReadingTarget, operation string) error {
+	query := "INSERT INTO calendar_outbox (target_id, user_id, operation, google_calendar_id) VALUES ($1, $2, $3, $4)"
+	_, err := tx.Exec(query, readingTarget.ID, readingTarget.UserID, operation, readingTarget.GoogleCalendarID)
+	return err
+}
+
+func withTx(conn *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+type postgresCalendarOutboxStore struct {
+	db *sql.DB
+}
+
+func (s *postgresCalendarOutboxStore) Claim(now time.Time, lease time.Duration, limit int) ([]dto.CalendarOutbox, error) {
+	query := `
+        WITH claimed AS (
+            UPDATE calendar_outbox SET locked_until = $3
+            WHERE id IN (
+                SELECT o.id FROM calendar_outbox o
+                WHERE o.status = $1 AND o.next_attempt_at <= $2
+                AND (o.locked_until IS NULL OR o.locked_until <= $2)
+                AND NOT EXISTS (
+                    SELECT 1 FROM calendar_outbox p
+                    WHERE p.target_id = o.target_id AND p.status = $1 AND p.id < o.id
+                )
+                ORDER BY o.id
+                LIMIT $4
+                FOR UPDATE SKIP LOCKED
+            )
+            RETURNING ` + calendarOutboxColumns + `
+        )
+        SELECT ` + calendarOutboxColumns + ` FROM claimed ORDER BY id
+    `
+	rows, err := s.db.Query(query, dto.CalendarSyncPending, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []dto.CalendarOutbox
+	for rows.Next() {
+		var entry dto.CalendarOutbox
+		err := rows.Scan(&entry.ID, &entry.TargetID, &entry.UserID, &entry.Operation, &entry.GoogleCalendarID, &entry.Status, &entry.Attempts, &entry.NextAttemptAt, &entry.LastError, &entry.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+func (s *postgresCalendarOutboxStore) Update(entry dto.CalendarOutbox) error {
+	return updateCalendarOutbox(s.db, entry)
+}
+
+func (s *postgresCalendarOutboxStore) Complete(entry dto.CalendarOutbox, googleCalendarID string, calendarSync *dto.CalendarSync) error {
+	return withTx(s.db, func(tx *sql.Tx) error {
+		if err := updateCalendarOutbox(tx, entry); err != nil {
+			return err
+		}
+		if calendarSync == nil {
+			return nil
+		}
+		return updateCalendarSync(tx, entry.TargetID, googleCalendarID, *calendarSync)
+	})
+}
+
+func updateCalendarOutbox(conn execer, entry dto.CalendarOutbox) error {
+	query := "UPDATE calendar_outbox SET status = $1, attempts = $2, next_attempt_at = $3, last_error = $4, locked_until = NULL WHERE id = $5"
+	_, err := conn.Exec(query, entry.Status, entry.Attempts, entry.NextAttemptAt, entry.LastError, entry.ID)
+	return err
+}
+
+type postgresReadingProgressStore struct {
+	db *sql.DB
+}
+
+func scanReadingProgress(row rowScanner) (dto.ReadingProgress, error) {
+	var readingProgress dto.ReadingProgress
+	err := row.Scan(&readingProgress.ID, &readingProgress.UserID, &readingProgress.TargetID, &readingProgress.CurrentPage, &readingProgress.TimeStamp, &readingProgress.ReadAt, &readingProgress.Kind,
+		&readingProgress.StartVerse, &readingProgress.EndVerse, &readingProgress.PageShare)
+	return readingProgress, err
+}
+
+func (s *postgresReadingProgressStore) query(query string, args ...interface{}) ([]dto.ReadingProgress, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var readingProgresses []dto.ReadingProgress
+	for rows.Next() {
+		readingProgress, err := scanReadingProgress(rows)
+		if err != nil {
+			return nil, err
+		}
+		readingProgresses = append(readingProgresses, readingProgress)
+	}
+	return readingProgresses, rows.Err()
+}
+
+func (s *postgresReadingProgressStore) GetAll() ([]dto.ReadingProgress, error) {
+	return s.query("SELECT " + readingProgressColumns + " FROM reading_progress")
+}
+
+func (s *postgresReadingProgressStore) GetByID(id int) (dto.ReadingProgress, error) {
+	readingProgress, err := scanReadingProgress(s.db.QueryRow("SELECT "+readingProgressColumns+" FROM reading_progress WHERE progress_id = $1", id))
+	return readingProgress, notFound(err)
+}
+
+func (s *postgresReadingProgressStore) GetByUserID(userID int) ([]dto.ReadingProgress, error) {
+	return s.query("SELECT "+readingProgressColumns+" FROM reading_progress WHERE user_id = $1 ORDER BY read_at ASC", userID)
+}
+
+func (s *postgresReadingProgressStore) GetByUserIDTargetID(userID, targetID int) ([]dto.ReadingProgress, error) {
+	return s.query("SELECT "+readingProgressColumns+" FROM reading_progress WHERE user_id = $1 AND target_id = $2 ORDER BY read_at DESC", userID, targetID)
+}
+
+func (s *postgresReadingProgressStore) GetByTargetIDsAndTimeRange(targetIDs []int, startTime, endTime time.Time) ([]dto.ReadingProgress, error) {
+	inClause := helpers.BuildInClause(targetIDs)
+	if len(inClause) == 0 {
+		return nil, errors.New("empty list of target IDs")
+	}
+
+	query := fmt.Sprintf(`
+        SELECT %s FROM reading_progress
+        WHERE target_id IN (%s)
+        AND read_at >= $1 AND read_at <= $2
+    `, readingProgressColumns, inClause)
+	return s.query(query, startTime.UTC(), endTime.UTC())
+}
+
+const insertReadingProgress = `INSERT INTO reading_progress (user_id, target_id, current_page, read_at, kind, start_verse, end_verse, page_share)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	ON CONFLICT (user_id, target_id, current_page) WHERE kind = 'read' AND start_verse = '' DO NOTHING
+	RETURNING progress_id, last_update_timestamp, read_at, kind, page_share`
+
+// readAtParam returns the read time to insert in UTC, now when it is not set.
+func readAtParam(readingProgress *dto.ReadingProgress) time.Time {
+	if readingProgress.ReadAt.IsZero() {
+		return time.Now().UTC()
+	}
+	return readingProgress.ReadAt.UTC()
+}
+
+// kindParam returns the kind to insert, read when it is not set.
+func kindParam(readingProgress *dto.ReadingProgress) string {
+	if readingProgress.Kind == "" {
+		return dto.ProgressKindRead
+	}
+	return readingProgress.Kind
+}
+
+// pageShareParam returns the page share to insert, a whole page when it is
+// not set.
+func pageShareParam(readingProgress *dto.ReadingProgress) float64 {
+	if readingProgress.PageShare == 0 {
+		return 1
+	}
+	return readingProgress.PageShare
+}
+
+func insertReadingProgressArgs(readingProgress *dto.ReadingProgress) []interface{} {
+	return []interface{}{readingProgress.UserID, readingProgress.TargetID, readingProgress.CurrentPage, readAtParam(readingProgress),
+		kindParam(readingProgress), readingProgress.StartVerse, readingProgress.EndVerse, pageShareParam(readingProgress)}
+}
+
+func (s *postgresReadingProgressStore) Create(readingProgress *dto.ReadingProgress) error {
+	err := s.db.QueryRow(insertReadingProgress, insertReadingProgressArgs(readingProgress)...).Scan(&readingProgress.ID, &readingProgress.TimeStamp, &readingProgress.ReadAt, &readingProgress.Kind, &readingProgress.PageShare)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrConflict
+	}
+	return err
+}
+
+func (s *postgresReadingProgressStore) CreateBatch(readingProgresses []*dto.ReadingProgress) error {
+	return withTx(s.db, func(tx *sql.Tx) error {
+		for _, readingProgress := range readingProgresses {
+			err := tx.QueryRow(insertReadingProgress, insertReadingProgressArgs(readingProgress)...).Scan(&readingProgress.ID, &readingProgress.TimeStamp, &readingProgress.ReadAt, &readingProgress.Kind, &readingProgress.PageShare)
+			if errors.Is(err, sql.ErrNoRows) {
+				readingProgress.ID = 0
+			} else if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *postgresReadingProgressStore) Update(readingProgress dto.ReadingProgress) error {
+	query := "UPDATE reading_progress SET current_page = $1, start_verse = $2, end_verse = $3, page_share = $4 WHERE progress_id = $5"
+	_, err := s.db.Exec(query, readingProgress.CurrentPage, readingProgress.StartVerse, readingProgress.EndVerse, readingProgress.PageShare, readingProgress.ID)
+	return uniqueViolation(err)
+}
+
+func (s *postgresReadingProgressStore) Delete(id int) error {
+	_, err := s.db.Exec("DELETE FROM reading_progress WHERE progress_id = $1", id)
+	return err
+}
+
+type postgresIdempotencyStore struct {
+	db *sql.DB
+}
+
+func (s *postgresIdempotencyStore) Reserve(record *dto.IdempotencyRecord, expiredBefore time.Time) (bool, error) {
+	query := `
+        INSERT INTO idempotency_keys (idempotency_key, request_hash, created_at) VALUES ($1, $2, $3)
+        ON CONFLICT (idempotency_key) DO UPDATE
+        SET request_hash = EXCLUDED.request_hash, status_code = 0, response = '', created_at = EXCLUDED.created_at
+        WHERE idempotency_keys.created_at < $4
+        RETURNING created_at
+    `
+	err := s.db.QueryRow(query, record.Key, record.RequestHash, time.Now(), expiredBefore).Scan(&record.CreatedAt)
+	if err == nil {
+		return true, nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+
+	var response string
+	query = "SELECT request_hash, status_code, response, created_at FROM idempotency_keys WHERE idempotency_key = $1"
+	err = s.db.QueryRow(query, record.Key).Scan(&record.RequestHash, &record.StatusCode, &response, &record.CreatedAt)
+	if err != nil {
+		return false, notFound(err)
+	}
+	record.Response = []byte(response)
+	return false, nil
+}
+
+func (s *postgresIdempotencyStore) Complete(record dto.IdempotencyRecord) error {
+	_, err := s.db.Exec("UPDATE idempotency_keys SET status_code = $1, response = $2 WHERE idempotency_key = $3", record.StatusCode, string(record.Response), record.Key)
+	return err
+}
+
+func (s *postgresIdempotencyStore) Release(key string) error {
+	_, err := s.db.Exec("DELETE FROM idempotency_keys WHERE idempotency_key = $1", key)
+	return err
+}
+
+type postgresReadingStatsStore struct {
+	db *sql.DB
+}
+
+// localTimestamp converts the stored UTC read time to the timezone in $tz.
+func localTimestamp(tz string) string {
+	return "((read_at AT TIME ZONE 'UTC') AT TIME ZONE " + tz + ")"
+}
+
+func (s *postgresReadingStatsStore) GetPagesPerPeriod(userID int, period string, timezone string, from, to time.Time) ([]dto.PeriodPages, error) {
+	query := `
+        SELECT to_char(p.period, 'YYYY-MM-DD'), COALESCE(c.pages, 0), COALESCE(c.review_pages, 0)
+        FROM generate_series(date_trunc($2, $3::timestamp), date_trunc($2, $4::timestamp), ('1 ' || $2)::interval) AS p(period)
+        LEFT JOIN (
+            SELECT date_trunc($2, ` + localTimestamp("$5") + `) AS period, ROUND(SUM(page_share), 2) AS pages,
+                ROUND(COALESCE(SUM(page_share) FILTER (WHERE kind = 'review'), 0), 2) AS review_pages
+            FROM reading_progress
+            WHERE user_id = $1
+            AND ` + localTimestamp("$5") + ` >= date_trunc($2, $3::timestamp)
+            AND ` + localTimestamp("$5") + ` < date_trunc($2, $4::timestamp) + ('1 ' || $2)::interval
+            GROUP BY 1
+        ) AS c ON c.period = p.period
+        ORDER BY p.period
+    `
+	rows, err := s.db.Query(query, userID, period, from.Format("2006-01-02"), to.Format("2006-01-02"), timezone)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	periodPages := make([]dto.PeriodPages, 0)
+	for rows.Next() {
+		var pages dto.PeriodPages
+		if err := rows.Scan(&pages.Date, &pages.Pages, &pages.ReviewPages); err != nil {
+			return nil, err
+		}
+		periodPages = append(periodPages, pages)
+	}
+	return periodPages, rows.Err()
+}
+
+func (s *postgresReadingStatsStore) GetSummary(userID int, timezone string) (dto.ReadingSummary, error) {
+	query := `
+        WITH days AS (
+            SELECT ` + localTimestamp("$2") + `::date AS day, ROUND(SUM(page_share), 2) AS pages,
+                ROUND(COALESCE(SUM(page_share) FILTER (WHERE kind = 'review'), 0), 2) AS review_pages
+            FROM reading_progress
+            WHERE user_id = $1
+            GROUP BY 1
+        ), best AS (
+            SELECT day, pages, review_pages FROM days ORDER BY pages DESC, day DESC LIMIT 1
+        )
+        SELECT COALESCE(SUM(pages), 0), COALESCE(SUM(review_pages), 0), COUNT(*), COALESCE(to_char(MIN(day), 'YYYY-MM-DD'), ''),
+            COALESCE((SELECT to_char(day, 'YYYY-MM-DD') FROM best), ''),
+            COALESCE((SELECT pages FROM best), 0),
+            COALESCE((SELECT review_pages FROM best), 0)
+        FROM days
+    `
+	var summary dto.ReadingSummary
+	err := s.db.QueryRow(query, userID, timezone).Scan(&summary.TotalPages, &summary.ReviewPages, &summary.ActiveDays, &summary.FirstDay, &summary.BestDay, &summary.BestDayPages, &summary.BestDayReviewPages)
+	return summary, err
+}
+
+func (s *postgresReadingStatsStore) GetTargetPages(userID int) ([]dto.TargetPages, error) {
+	rows, err := s.db.Query("SELECT target_id, ROUND(SUM(page_share), 2) FROM reading_progress WHERE user_id = $1 AND kind = 'read' GROUP BY target_id ORDER BY target_id", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var targetPages []dto.TargetPages
+	for rows.Next() {
+		var pages dto.TargetPages
+		if err := rows.Scan(&pages.TargetID, &pages.PagesRead); err != nil {
+			return nil, err
+		}
+		targetPages = append(targetPages, pages)
+	}
+	return targetPages, rows.Err()
+}
+
+type postgresHifzStore struct {
+	db *sql.DB
+}
+
+func scanHifzPage(row rowScanner) (dto.HifzPage, error) {
+	var hifzPage dto.HifzPage
+	var lastReviewedAt sql.NullTime
+	err := row.Scan(&hifzPage.ID, &hifzPage.UserID, &hifzPage.Page, &hifzPage.FirstVerse, &hifzPage.LastVerse, &hifzPage.Repetitions,
+		&hifzPage.IntervalDays, &hifzPage.EaseFactor, &hifzPage.DueDate, &hifzPage.MemorizedAt, &lastReviewedAt)
+	if lastReviewedAt.Valid {
+		hifzPage.LastReviewedAt = &lastReviewedAt.Time
+	}
+	return hifzPage, err
+}
+
+func (s *postgresHifzStore) query(query string, args ...interface{}) ([]dto.HifzPage, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hifzPages := make([]dto.HifzPage, 0)
+	for rows.Next() {
+		hifzPage, err := scanHifzPage(rows)
+		if err != nil {
+			return nil, err
+		}
+		hifzPages = append(hifzPages, hifzPage)
+	}
+	return hifzPages, rows.Err()
+}
+
+func (s *postgresHifzStore) GetByUserID(userID int) ([]dto.HifzPage, error) {
+	return s.query("SELECT "+hifzPageColumns+" FROM hifz_pages WHERE user_id = $1 ORDER BY page", userID)
+}
+
+func (s *postgresHifzStore) GetByUserIDPage(userID, page int) (dto.HifzPage, error) {
+	hifzPage, err := scanHifzPage(s.db.QueryRow("SELECT "+hifzPageColumns+" FROM hifz_pages WHERE user_id = $1 AND page = $2", userID, page))
+	return hifzPage, notFound(err)
+}
+
+func (s *postgresHifzStore) GetDue(userID int, date string) ([]dto.HifzPage, error) {
+	return s.query("SELECT "+hifzPageColumns+" FROM hifz_pages WHERE user_id = $1 AND due_date <= $2::date ORDER BY due_date, page", userID, date)
+}
+
+func (s *postgresHifzStore) Save(hifzPage *dto.HifzPage) error {
+	query := `
+        INSERT INTO hifz_pages (user_id, page, first_verse, last_verse, repetitions, interval_days, ease_factor, due_date, memorized_at, last_reviewed_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8::date, $9, $10)
+        ON CONFLICT (user_id, page) DO UPDATE
+        SET first_verse = EXCLUDED.first_verse, last_verse = EXCLUDED.last_verse, repetitions = EXCLUDED.repetitions,
+            interval_days = EXCLUDED.interval_days, ease_factor = EXCLUDED.ease_factor, due_date = EXCLUDED.due_date,
+            memorized_at = EXCLUDED.memorized_at, last_reviewed_at = EXCLUDED.last_reviewed_at
+        RETURNING hifz_id
+    `
+	return s.db.QueryRow(query, hifzPage.UserID, hifzPage.Page, hifzPage.FirstVerse, hifzPage.LastVerse, hifzPage.Repetitions,
+		hifzPage.IntervalDays, hifzPage.EaseFactor, hifzPage.DueDate, hifzPage.MemorizedAt, hifzPage.LastReviewedAt).Scan(&hifzPage.ID)
+}
+
+func (s *postgresHifzStore) SaveReview(hifzPage dto.HifzPage, review *dto.HifzReview) error {
+	return withTx(s.db, func(tx *sql.Tx) error {
+		query := `
+            UPDATE hifz_pages SET repetitions = $1, interval_days = $2, ease_factor = $3, due_date = $4::date, last_reviewed_at = $5
+            WHERE user_id = $6 AND page = $7
+        `
+		result, err := tx.Exec(query, hifzPage.Repetitions, hifzPage.IntervalDays, hifzPage.EaseFactor, hifzPage.DueDate, hifzPage.LastReviewedAt, hifzPage.UserID, hifzPage.Page)
+		if err != nil {
+			return err
+		}
+		if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+			return ErrNotFound
+		}
+
+		query = `
+            INSERT INTO hifz_reviews (user_id, page, quality, interval_days, ease_factor, due_date, reviewed_at)
+            VALUES ($1, $2, $3, $4, $5, $6::date, $7)
+            RETURNING review_id
+        `
+		return tx.QueryRow(query, review.UserID, review.Page, review.Quality, review.IntervalDays, review.EaseFactor, review.DueDate, review.ReviewedAt).Scan(&review.ID)
+	})
+}
+
+func (s *postgresHifzStore) GetReviews(userID, page int) ([]dto.HifzReview, error) {
+	rows, err := s.db.Query("SELECT "+hifzReviewColumns+" FROM hifz_reviews WHERE user_id = $1 AND page = $2 ORDER BY reviewed_at DESC", userID, page)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := make([]dto.HifzReview, 0)
+	for rows.Next() {
+		var review dto.HifzReview
+		err := rows.Scan(&review.ID, &review.UserID, &review.Page, &review.Quality, &review.IntervalDays, &review.EaseFactor, &review.DueDate, &review.ReviewedAt)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+	return reviews, rows.Err()
+}
+
+func (s *postgresHifzStore) Delete(userID, page int) error {
+	return withTx(s.db, func(tx *sql.Tx) error {
+		if _, err := tx.Exec("DELETE FROM hifz_reviews WHERE user_id = $1 AND page = $2", userID, page); err != nil {
+			return err
+		}
+		_, err := tx.Exec("DELETE FROM hifz_pages WHERE user_id = $1 AND page = $2", userID, page)
+		return err
+	})
+}
+
+type postgresBookmarkStore struct {
+	db *sql.DB
+}
+
+func (s *postgresBookmarkStore) Get(userID int) (dto.BookmarkPin, error) {
+	var bookmark dto.BookmarkPin
+	err := s.db.QueryRow("SELECT user_id, target_id, page, verse_key, updated_at FROM bookmarks WHERE user_id = $1", userID).
+		Scan(&bookmark.UserID, &bookmark.TargetID, &bookmark.Page, &bookmark.VerseKey, &bookmark.UpdatedAt)
+	return bookmark, notFound(err)
+}
+
+func (s *postgresBookmarkStore) Save(bookmark *dto.BookmarkPin) error {
+	query := `
+        INSERT INTO bookmarks (user_id, target_id, page, verse_key, updated_at) VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (user_id) DO UPDATE
+        SET target_id = EXCLUDED.target_id, page = EXCLUDED.page, verse_key = EXCLUDED.verse_key, updated_at = EXCLUDED.updated_at
+    `
+	_, err := s.db.Exec(query, bookmark.UserID, bookmark.TargetID, bookmark.Page, bookmark.VerseKey, bookmark.UpdatedAt.UTC())
+	return err
+}
+
+func (s *postgresBookmarkStore) Delete(userID int) error {
+	_, err := s.db.Exec("DELETE FROM bookmarks WHERE user_id = $1", userID)
+	return err
+}
+
+type postgresReflectionStore struct {
+	db *sql.DB
+}
+
+func scanReflection(row rowScanner) (dto.Reflection, error) {
+	var reflection dto.Reflection
+	var progressID sql.NullInt64
+	err := row.Scan(&reflection.ID, &reflection.UserID, &progressID, &reflection.Page, &reflection.VerseKey, &reflection.Note,
+		pq.Array(&reflection.Tags), &reflection.IsPublic, &reflection.CreatedAt, &reflection.UpdatedAt)
+	reflection.ProgressID = int(progressID.Int64)
+	return reflection, err
+}
+
+func (s *postgresReflectionStore) query(query string, args ...interface{}) ([]dto.Reflection, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reflections := make([]dto.Reflection, 0)
+	for rows.Next() {
+		reflection, err := scanReflection(rows)
+		if err != nil {
+			return nil, err
+		}
+		reflections = append(reflections, reflection)
+	}
+	return reflections, rows.Err()
+}
+
+func (s *postgresReflectionStore) GetByID(id int) (dto.Reflection, error) {
+	reflection, err := scanReflection(s.db.QueryRow("SELECT "+reflectionColumns+" FROM reflections WHERE reflection_id = $1", id))
+	return reflection, notFound(err)
+}
+
+func (s *postgresReflectionStore) Search(userID int, filter dto.ReflectionFilter) ([]dto.Reflection, error) {
+	query := `
+        SELECT ` + reflectionColumns + ` FROM reflections
+        WHERE user_id = $1
+        AND ($2 = '' OR to_tsvector('simple', note) @@ plainto_tsquery('simple', $2) OR lower($2) = ANY(tags))
+        AND ($3 = '' OR $3 = ANY(tags))
+        AND ($4 = 0 OR progress_id = $4)
+        ORDER BY created_at DESC, reflection_id DESC
+    `
+	return s.query(query, userID, filter.Query, filter.Tag, filter.ProgressID)
+}
+
+func (s *postgresReflectionStore) GetPublic(verseKey string, limit int) ([]dto.Reflection, error) {
+	query := `
+        SELECT ` + reflectionColumns + ` FROM reflections
+        WHERE is_public AND ($1 = '' OR verse_key = $1)
+        ORDER BY created_at DESC, reflection_id DESC
+        LIMIT $2
+    `
+	return s.query(query, verseKey, limit)
+}
+
+func (s *postgresReflectionStore) Create(reflection *dto.Reflection) error {
+	query := `
+        INSERT INTO reflections (user_id, progress_id, page, verse_key, note, tags, is_public)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING reflection_id, created_at, updated_at
+    `
+	return s.db.QueryRow(query, reflection.UserID, reflection.ProgressID, reflection.Page, reflection.VerseKey, reflection.Note,
+		pq.Array(reflection.Tags), reflection.IsPublic).Scan(&reflection.ID, &reflection.CreatedAt, &reflection.UpdatedAt)
+}
+
+func (s *postgresReflectionStore) Update(reflection *dto.Reflection) error {
+	query := `
+        UPDATE reflections SET verse_key = $1, note = $2, tags = $3, is_public = $4, updated_at = CURRENT_TIMESTAMP
+        WHERE reflection_id = $5
+        RETURNING updated_at
+    `
+	err := s.db.QueryRow(query, reflection.VerseKey, reflection.Note, pq.Array(reflection.Tags), reflection.IsPublic, reflection.ID).Scan(&reflection.UpdatedAt)
+	return notFound(err)
+}
+
+func (s *postgresReflectionStore) Delete(id int) error {
+	_, err := s.db.Exec("DELETE FROM reflections WHERE reflection_id = $1", id)
+	return err
+}
+
+type postgresKhatamStore struct {
+	db *sql.DB
+}
+
+func (s *postgresKhatamStore) GetByUserID(userID int) ([]dto.Khatam, error) {
+	rows, err := s.db.Query("SELECT "+khatamColumns+" FROM khatams WHERE user_id = $1 ORDER BY completed_at DESC, khatam_id DESC", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	khatams := make([]dto.Khatam, 0)
+	for rows.Next() {
+		var khatam dto.Khatam
+		err := rows.Scan(&khatam.ID, &khatam.UserID, &khatam.TargetID, &khatam.TargetName, &khatam.StartPage, &khatam.EndPage,
+			&khatam.StartedAt, &khatam.CompletedAt, &khatam.DurationDays)
+		if err != nil {
+			return nil, err
+		}
+		khatams = append(khatams, khatam)
+	}
+	return khatams, rows.Err()
+}
+
+func (s *postgresKhatamStore) Create(khatam *dto.Khatam) error {
+	query := `
+        INSERT INTO khatams (user_id, target_id, target_name, start_page, end_page, started_at, completed_at, duration_days)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING khatam_id
+    `
+	err := s.db.QueryRow(query, khatam.UserID, khatam.TargetID, khatam.TargetName, khatam.StartPage, khatam.EndPage,
+		khatam.StartedAt.UTC(), khatam.CompletedAt.UTC(), khatam.DurationDays).Scan(&khatam.ID)
+	return uniqueViolation(err)
+}
+
+type postgresGroupStore struct {
+	db *sql.DB
+}
+
+func (s *postgresGroupStore) GetByID(id int) (dto.Group, error) {
+	var group dto.Group
+	err := s.db.QueryRow("SELECT group_id, name, owner_id, created_at FROM study_groups WHERE group_id = $1", id).
+		Scan(&group.ID, &group.Name, &group.OwnerID, &group.CreatedAt)
+	if err != nil {
+		return dto.Group{}, notFound(err)
+	}
+
+	query := `
+        SELECT m.user_id, u.username, COALESCE(u.display_name, ''), m.joined_at
+        FROM study_group_members m JOIN users u ON u.id = m.user_id
+        WHERE m.group_id = $1
+        ORDER BY m.joined_at, m.user_id
+    `
+	rows, err := s.db.Query(query, id)
+	if err != nil {
+		return dto.Group{}, err
+	}
+	defer rows.Close()
+
+	group.Members = make([]dto.GroupMember, 0)
+	for rows.Next() {
+		var member dto.GroupMember
+		if err := rows.Scan(&member.UserID, &member.Username, &member.DisplayName, &member.JoinedAt); err != nil {
+			return dto.Group{}, err
+		}
+		group.Members = append(group.Members, member)
+	}
+	return group, rows.Err()
+}
+
+func (s *postgresGroupStore) GetByUserID(userID int) ([]dto.Group, error) {
+	query := `
+        SELECT g.group_id, g.name, g.owner_id, g.created_at
+        FROM study_groups g JOIN study_group_members m ON m.group_id = g.group_id
+        WHERE m.user_id = $1
+        ORDER BY g.group_id
+    `
+	rows, err := s.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := make([]dto.Group, 0)
+	for rows.Next() {
+		var group dto.Group
+		if err := rows.Scan(&group.ID, &group.Name, &group.OwnerID, &group.CreatedAt); err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+	return groups, rows.Err()
+}
+
+func (s *postgresGroupStore) Create(group *dto.Group) error {
+	return withTx(s.db, func(tx *sql.Tx) error {
+		err := tx.QueryRow("INSERT INTO study_groups (name, owner_id) VALUES ($1, $2) RETURNING group_id, created_at", group.Name, group.OwnerID).
+			Scan(&group.ID, &group.CreatedAt)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec("INSERT INTO study_group_members (group_id, user_id) VALUES ($1, $2)", group.ID, group.OwnerID)
+		return err
+	})
+}
+
+func (s *postgresGroupStore) Delete(id int) error {
+	_, err := s.db.Exec("DELETE FROM study_groups WHERE group_id = $1", id)
+	return err
+}
+
+func (s *postgresGroupStore) AddMember(groupID, userID int) error {
+	_, err := s.db.Exec("INSERT INTO study_group_members (group_id, user_id) VALUES ($1, $2)", groupID, userID)
+	return uniqueViolation(err)
+}
+
+func (s *postgresGroupStore) RemoveMember(groupID, userID int) error {
+	_, err := s.db.Exec("DELETE FROM study_group_members WHERE group_id = $1 AND user_id = $2", groupID, userID)
+	return err
+}
+
+func scanGroupTarget(row rowScanner) (dto.GroupTarget, error) {
+	var groupTarget dto.GroupTarget
+	err := row.Scan(&groupTarget.ID, &groupTarget.GroupID, &groupTarget.Name, &groupTarget.StartDate, &groupTarget.EndDate,
+		&groupTarget.StartPage, &groupTarget.EndPage, &groupTarget.CreatedAt)
+	return groupTarget, err
+}
+
+// assignments fills the assignments of the given targets, ordered by page.
+func (s *postgresGroupStore) assignments(groupTargets []dto.GroupTarget) error {
+	if len(groupTargets) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(groupTargets))
+	index := make(map[int]int)
+	for i := range groupTargets {
+		ids[i] = int64(groupTargets[i].ID)
+		index[groupTargets[i].ID] = i
+		groupTargets[i].Assignments = make([]dto.GroupAssignment, 0)
+	}
+
+	query := `
+        SELECT ` + groupAssignmentColumns + `
+        FROM group_assignments a JOIN users u ON u.id = a.user_id
+        WHERE a.group_target_id = ANY($1)
+        ORDER BY a.start_page, a.assignment_id
+    `
+	rows, err := s.db.Query(query, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var assignment dto.GroupAssignment
+		err := rows.Scan(&assignment.ID, &assignment.GroupTargetID, &assignment.UserID, &assignment.Username, &assignment.TargetID,
+			&assignment.StartPage, &assignment.EndPage, &assignment.Status)
+		if err != nil {
+			return err
+		}
+		i := index[assignment.GroupTargetID]
+		groupTargets[i].Assignments = append(groupTargets[i].Assignments, assignment)
+	}
+	return rows.Err()
+}
+
+func (s *postgresGroupStore) GetTargets(groupID int) ([]dto.GroupTarget, error) {
+	rows, err := s.db.Query("SELECT "+groupTargetColumns+" FROM group_targets WHERE group_id = $1 ORDER BY group_target_id", groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groupTargets := make([]dto.GroupTarget, 0)
+	for rows.Next() {
+		groupTarget, err := scanGroupTarget(rows)
+		if err != nil {
+			return nil, err
+		}
+		groupTargets = append(groupTargets, groupTarget)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return groupTargets, s.assignments(groupTargets)
+}
+
+func (s *postgresGroupStore) GetTarget(id int) (dto.GroupTarget, error) {
+	groupTarget, err := scanGroupTarget(s.db.QueryRow("SELECT "+groupTargetColumns+" FROM group_targets WHERE group_target_id = $1", id))
+	if err != nil {
+		return dto.GroupTarget{}, notFound(err)
+	}
+	groupTargets := []dto.GroupTarget{groupTarget}
+	err = s.assignments(groupTargets)
+	return groupTargets[0], err
+}
+
+func (s *postgresGroupStore) CreateTarget(groupTarget *dto.GroupTarget) error {
+	return withTx(s.db, func(tx *sql.Tx) error {
+		query := `
+            INSERT INTO group_targets (group_id, name, start_date, end_date, start_page, end_page)
+            VALUES ($1, $2, $3, $4, $5, $6)
+            RETURNING group_target_id, created_at
+        `
+		err := tx.QueryRow(query, groupTarget.GroupID, groupTarget.Name, groupTarget.StartDate, groupTarget.EndDate, groupTarget.StartPage, groupTarget.EndPage).
+			Scan(&groupTarget.ID, &groupTarget.CreatedAt)
+		if err != nil {
+			return err
+		}
+		for i := range groupTarget.Assignments {
+			if err := createGroupAssignment(tx, *groupTarget, &groupTarget.Assignments[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *postgresGroupStore) Reassign(groupTarget dto.GroupTarget, assignmentID int, assignments []*dto.GroupAssignment) error {
+	return withTx(s.db, func(tx *sql.Tx) error {
+		query := "UPDATE group_assignments SET status = $1 WHERE assignment_id = $2 AND group_target_id = $3 AND status = $4 RETURNING target_id"
+		var targetID int
+		err := tx.QueryRow(query, dto.GroupAssignmentReassigned, assignmentID, groupTarget.ID, dto.GroupAssignmentActive).Scan(&targetID)
+		if err != nil {
+			return notFound(err)
+		}
+		_, err = tx.Exec("UPDATE reading_target SET status = $1, paused_at = NULL WHERE target_id = $2", dto.TargetStatusReassigned, targetID)
+		if err != nil {
+			return err
+		}
+		for _, assignment := range assignments {
+			if err := createGroupAssignment(tx, groupTarget, assignment); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// assignmentTarget is the personal reading target created for a member's
+// part of a group target.
+func assignmentTarget(groupTarget dto.GroupTarget, assignment dto.GroupAssignment) dto.ReadingTarget {
+	var scope *dto.TargetScope
+	if assignment.FirstJuz > 0 {
+		scope = &dto.TargetScope{Juz: dto.ScopeRange{assignment.FirstJuz, assignment.LastJuz}}
+	}
+	return dto.ReadingTarget{
+		UserID:    assignment.UserID,
+		Name:      groupTarget.Name,
+		StartDate: groupTarget.StartDate,
+		EndDate:   groupTarget.EndDate,
+		StartPage: assignment.StartPage,
+		EndPage:   assignment.EndPage,
+		Scope:     scope,
+		Pages:     float64(assignment.EndPage - assignment.StartPage + 1),
+		Status:    dto.TargetStatusActive,
+	}
+}
+
+func createGroupAssignment(tx *sql.Tx, groupTarget dto.GroupTarget, assignment *dto.GroupAssignment) error {
+	readingTarget := assignmentTarget(groupTarget, *assignment)
+	if err := createReadingTargetWithCalendarSync(tx, &readingTarget); err != nil {
+		return err
+	}
+	assignment.GroupTargetID = groupTarget.ID
+	assignment.TargetID = readingTarget.ID
+	assignment.Status = dto.GroupAssignmentActive
+	query := `
+        INSERT INTO group_assignments (group_target_id, user_id, target_id, start_page, end_page, status)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING assignment_id
+    `
+	return tx.QueryRow(query, assignment.GroupTargetID, assignment.UserID, assignment.TargetID, assignment.StartPage, assignment.EndPage, assignment.Status).
+		Scan(&assignment.ID)
+}
+
+type postgresTargetTemplateStore struct {
+	db *sql.DB
+}
+
+func scanTargetTemplate(row rowScanner) (dto.TargetTemplate, error) {
+	var template dto.TargetTemplate
+	var weekday sql.NullInt64
+	err := row.Scan(&template.ID, &template.Name, &template.Description, &template.StartPage, &template.EndPage, &template.Pages, &template.DurationDays, &weekday, &template.Recurrence, &template.CreatedAt)
+	if weekday.Valid {
+		day := int(weekday.Int64)
+		template.Weekday = &day
+	}
+	return template, err
+}
+
+func (s *postgresTargetTemplateStore) GetAll() ([]dto.TargetTemplate, error) {
+	rows, err := s.db.Query("SELECT " + targetTemplateColumns + " FROM target_templates ORDER BY template_id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := make([]dto.TargetTemplate, 0)
+	for rows.Next() {
+		template, err := scanTargetTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+	return templates, rows.Err()
+}
+
+func (s *postgresTargetTemplateStore) GetByID(id int) (dto.TargetTemplate, error) {
+	template, err := scanTargetTemplate(s.db.QueryRow("SELECT "+targetTemplateColumns+" FROM target_templates WHERE template_id = $1", id))
+	return template, notFound(err)
+}
+
+func (s *postgresTargetTemplateStore) Create(template *dto.TargetTemplate) error {
+	query := "INSERT INTO target_templates (name, description, start_page, end_page, pages, duration_days, weekday, recurrence) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING template_id, created_at"
+	return s.db.QueryRow(query, template.Name, template.Description, template.StartPage, template.EndPage, template.Pages, template.DurationDays, template.Weekday, template.Recurrence).Scan(&template.ID, &template.CreatedAt)
+}
+
+func (s *postgresTargetTemplateStore) Delete(id int) error {
+	result, err := s.db.Exec("DELETE FROM target_templates WHERE template_id = $1", id)
+	if err != nil {
+		return err
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
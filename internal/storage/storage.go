@@ -0,0 +1,199 @@
+package storage
+
+import (
+	"errors"
+	"time"
+
+	"github.com/daffashafwan/tadarus-yuk/internal/dto"
+)
+
+// ErrNotFound is returned when the requested row does not exist.
+var ErrNotFound = errors.New("record not found")
+
+// ErrConflict is returned when a row would break a unique constraint.
+var ErrConflict = errors.New("record already exists")
+
+// UserStore persists users and admins.
+type UserStore interface {
+	GetAll() ([]dto.User, error)
+	GetByID(id int) (dto.User, error)
+	GetByUsername(username string) (dto.User, error)
+	GetByEmail(email string) (dto.User, error)
+	Create(user *dto.User, hashedPassword string) error
+	Update(user dto.User) error
+	Delete(id int) error
+	GetAdminByUsername(username string) (dto.Admin, error)
+}
+
+// ReadingTargetStore persists reading targets.
+type ReadingTargetStore interface {
+	GetAll() ([]dto.ReadingTarget, error)
+	GetByID(id int) (dto.ReadingTarget, error)
+	GetByUserID(userID int) ([]dto.ReadingTarget, error)
+	GetPublic() ([]dto.ReadingTarget, error)
+	Create(readingTarget *dto.ReadingTarget) error
+	Update(readingTarget dto.ReadingTarget) error
+	Delete(id int) error
+	// CreateWithCalendarSync, UpdateWithCalendarSync and DeleteWithCalendarSync
+	// write the target and queue the matching calendar change in one transaction.
+	CreateWithCalendarSync(readingTarget *dto.ReadingTarget) error
+	UpdateWithCalendarSync(readingTarget dto.ReadingTarget) error
+	DeleteWithCalendarSync(readingTarget dto.ReadingTarget) error
+	// UpdateCalendarSync records the sync result, an empty googleCalendarID
+	// keeps the stored one.
+	UpdateCalendarSync(targetID int, googleCalendarID string, calendarSync dto.CalendarSync) error
+	// GetRecurring returns the recurring targets without a next instance.
+	GetRecurring() ([]dto.ReadingTarget, error)
+	// CreateNext creates the targets that follow previous with their calendar
+	// changes and records the first as its next instance, in one transaction.
+	// It returns ErrConflict when previous already has a next instance.
+	CreateNext(previous dto.ReadingTarget, readingTargets []*dto.ReadingTarget) error
+}
+
+// CalendarOutboxStore reads and updates queued calendar changes.
+type CalendarOutboxStore interface {
+	// Claim locks pending entries whose next attempt is due for lease and
+	// returns them oldest first, skipping entries queued behind an unfinished
+	// entry of the same target and entries claimed by another worker.
+	Claim(now time.Time, lease time.Duration, limit int) ([]dto.CalendarOutbox, error)
+	// Update saves the entry and releases its claim.
+	Update(entry dto.CalendarOutbox) error
+	// Complete saves the entry and, when calendarSync is set, the sync result
+	// of its target in one transaction.
+	Complete(entry dto.CalendarOutbox, googleCalendarID string, calendarSync *dto.CalendarSync) error
+}
+
+// ReadingProgressStore persists reading progress entries.
+type ReadingProgressStore interface {
+	GetAll() ([]dto.ReadingProgress, error)
+	GetByID(id int) (dto.ReadingProgress, error)
+	// GetByUserID returns the user's progress ordered from oldest to newest.
+	GetByUserID(userID int) ([]dto.ReadingProgress, error)
+	// GetByUserIDTargetID returns the progress of a target ordered from newest to oldest.
+	GetByUserIDTargetID(userID, targetID int) ([]dto.ReadingProgress, error)
+	GetByTargetIDsAndTimeRange(targetIDs []int, startTime, endTime time.Time) ([]dto.ReadingProgress, error)
+	// Create returns ErrConflict when the page is already logged for the target.
+	Create(readingProgress *dto.ReadingProgress) error
+	// CreateBatch inserts every entry in one transaction. Pages already logged
+	// for the target are left out and keep an ID of 0.
+	CreateBatch(readingProgresses []*dto.ReadingProgress) error
+	// Update saves the page, verses and page share of an entry. It returns
+	// ErrConflict when the page is already logged for the target.
+	Update(readingProgress dto.ReadingProgress) error
+	Delete(id int) error
+}
+
+// ReadingStatsStore aggregates reading progress. Days are counted in the
+// given IANA timezone, progress timestamps are stored in UTC.
+type ReadingStatsStore interface {
+	// GetPagesPerPeriod returns the pages per day, week or month from the
+	// period holding from to the one holding to, including empty periods.
+	GetPagesPerPeriod(userID int, period string, timezone string, from, to time.Time) ([]dto.PeriodPages, error)
+	GetSummary(userID int, timezone string) (dto.ReadingSummary, error)
+	GetTargetPages(userID int) ([]dto.TargetPages, error)
+}
+
+// IdempotencyStore remembers the responses of requests sent with an
+// Idempotency-Key header.
+type IdempotencyStore interface {
+	// Reserve claims record.Key, or a key last claimed before expiredBefore.
+	// When the key is taken it returns false and fills record with the stored one.
+	Reserve(record *dto.IdempotencyRecord, expiredBefore time.Time) (bool, error)
+	Complete(record dto.IdempotencyRecord) error
+	Release(key string) error
+}
+
+// HifzStore persists memorized pages and their reviews.
+type HifzStore interface {
+	// GetByUserID returns the memorized pages of a user ordered by page.
+	GetByUserID(userID int) ([]dto.HifzPage, error)
+	GetByUserIDPage(userID, page int) (dto.HifzPage, error)
+	// GetDue returns the pages due on or before date, oldest due date first.
+	GetDue(userID int, date string) ([]dto.HifzPage, error)
+	// Save inserts the page or updates the stored page of the same user.
+	Save(hifzPage *dto.HifzPage) error
+	// SaveReview stores the review and the new schedule of its page in one
+	// transaction.
+	SaveReview(hifzPage dto.HifzPage, review *dto.HifzReview) error
+	GetReviews(userID, page int) ([]dto.HifzReview, error)
+	Delete(userID, page int) error
+}
+
+// BookmarkStore persists the bookmark pinned by each user.
+type BookmarkStore interface {
+	// Get returns ErrNotFound when the user has no pinned bookmark.
+	Get(userID int) (dto.BookmarkPin, error)
+	Save(bookmark *dto.BookmarkPin) error
+	Delete(userID int) error
+}
+
+// ReflectionStore persists the notes written on progress entries.
+type ReflectionStore interface {
+	GetByID(id int) (dto.Reflection, error)
+	// Search returns the notes of a user matching filter, newest first.
+	Search(userID int, filter dto.ReflectionFilter) ([]dto.Reflection, error)
+	// GetPublic returns the newest public notes, of one verse when verseKey
+	// is not empty.
+	GetPublic(verseKey string, limit int) ([]dto.Reflection, error)
+	Create(reflection *dto.Reflection) error
+	Update(reflection *dto.Reflection) error
+	Delete(id int) error
+}
+
+// KhatamStore persists the completed targets.
+type KhatamStore interface {
+	// GetByUserID returns the completions of a user, newest first.
+	GetByUserID(userID int) ([]dto.Khatam, error)
+	// Create returns ErrConflict when the target is already recorded.
+	Create(khatam *dto.Khatam) error
+}
+
+// GroupStore persists study groups, their members and shared targets.
+type GroupStore interface {
+	// GetByID returns the group with its members in joining order.
+	GetByID(id int) (dto.Group, error)
+	// GetByUserID returns the groups the user is a member of.
+	GetByUserID(userID int) ([]dto.Group, error)
+	// Create stores the group with its owner as the first member.
+	Create(group *dto.Group) error
+	// Delete removes the group and its targets, the personal reading targets
+	// of the members are kept.
+	Delete(id int) error
+	// AddMember returns ErrConflict when the user is already a member.
+	AddMember(groupID, userID int) error
+	RemoveMember(groupID, userID int) error
+	// GetTargets returns the targets of a group with their assignments.
+	GetTargets(groupID int) ([]dto.GroupTarget, error)
+	GetTarget(id int) (dto.GroupTarget, error)
+	// CreateTarget stores the target and its assignments, creating the
+	// personal reading target of every assignment in the same transaction.
+	CreateTarget(groupTarget *dto.GroupTarget) error
+	// Reassign marks an assignment and its reading target reassigned and
+	// stores its replacements with their reading targets in one transaction.
+	// It returns ErrNotFound when the assignment is no longer active.
+	Reassign(groupTarget dto.GroupTarget, assignmentID int, assignments []*dto.GroupAssignment) error
+}
+
+// TargetTemplateStore persists the catalogue of target templates.
+type TargetTemplateStore interface {
+	GetAll() ([]dto.TargetTemplate, error)
+	GetByID(id int) (dto.TargetTemplate, error)
+	Create(template *dto.TargetTemplate) error
+	Delete(id int) error
+}
+
+// Store groups every store used by the application.
+type Store struct {
+	Users           UserStore
+	ReadingTargets  ReadingTargetStore
+	ReadingProgress ReadingProgressStore
+	CalendarOutbox  CalendarOutboxStore
+	Idempotency     IdempotencyStore
+	ReadingStats    ReadingStatsStore
+	Hifz            HifzStore
+	Bookmarks       BookmarkStore
+	Reflections     ReflectionStore
+	Khatams         KhatamStore
+	Groups          GroupStore
+	Templates       TargetTemplateStore
+}
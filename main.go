@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"github.com/daffashafwan/tadarus-yuk/env"
 	"github.com/daffashafwan/tadarus-yuk/external"
 	"github.com/daffashafwan/tadarus-yuk/internal/authorization"
+	"github.com/daffashafwan/tadarus-yuk/internal/storage"
 	"github.com/daffashafwan/tadarus-yuk/routes"
 	appHandlers "github.com/daffashafwan/tadarus-yuk/handlers"
 	"github.com/gorilla/handlers"
@@ -19,18 +21,53 @@ func main() {
 	// Load environment variables
 	env.LoadEnv()
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		db.OpenDB()
+		if err := db.RunMigrationCommand(os.Args[2:]); err != nil {
+			log.Fatal("Migrate: ", err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "quran-cache" {
+		if len(os.Args) < 3 || os.Args[2] != "warm" {
+			log.Fatal("usage: quran-cache warm")
+		}
+		db.ConnectDB()
+		external.InitQuranAPI()
+		external.InitQuranCache(db.GetDB())
+		err := external.WarmQuranCache(context.Background())
+		stats, _ := external.GetQuranCacheStats()
+		log.Printf("Quran cache warmed, hits %d, misses %d", stats.MemoryHits+stats.PersistentHits, stats.Misses)
+		if err != nil {
+			log.Fatal("Warm quran cache: ", err)
+		}
+		return
+	}
+
 	// Connect to the database
 	db.ConnectDB()
 
 	external.InitQuranAPI()
+	external.InitQuranCache(db.GetDB())
 
 	authorization.InitSecret()
 
 	appHandlers.InitGoogle()
 
+	appHandlers.InitStore(storage.NewPostgresStore(db.GetDB()))
+
+	appHandlers.InitReadingProgress()
+
+	appHandlers.InitCalendarSync()
+	go appHandlers.RunCalendarSyncWorker(context.Background())
+
+	appHandlers.InitRecurringTargets()
+	go appHandlers.RunRecurringTargetWorker(context.Background())
+
 	router := mux.NewRouter()
 
-	headersOk := handlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization"})
+	headersOk := handlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization", "Idempotency-Key"})
 	originsOk := handlers.AllowedOrigins([]string{"*"})
 	methodsOk := handlers.AllowedMethods([]string{"GET", "HEAD", "POST", "PUT", "DELETE", "OPTIONS"})
 
@@ -1 +1 @@
-DROP TABLE IF EXISTS reading_progress;
\ No newline at end of file
+DROP TABLE IF EXISTS reading_target;
\ No newline at end of file
@@ -1,4 +1,4 @@
 ALTER TABLE reading_target
-ADD COLUMN name VARCHAR(255),
-ADD COLUMN start_page INT,
-ADD COLUMN end_page INT;
\ No newline at end of file
+ADD COLUMN IF NOT EXISTS name VARCHAR(255),
+ADD COLUMN IF NOT EXISTS start_page INT,
+ADD COLUMN IF NOT EXISTS end_page INT;
\ No newline at end of file
@@ -1,2 +1,2 @@
 ALTER TABLE users
-ADD COLUMN google_token VARCHAR(300);
\ No newline at end of file
+ADD COLUMN IF NOT EXISTS google_token VARCHAR(300);
\ No newline at end of file
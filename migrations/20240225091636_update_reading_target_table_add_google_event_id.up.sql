@@ -1,2 +1,2 @@
 ALTER TABLE reading_target
-ADD COLUMN google_calendar_id VARCHAR(300);
\ No newline at end of file
+ADD COLUMN IF NOT EXISTS google_calendar_id VARCHAR(300);
\ No newline at end of file
@@ -1,2 +1,2 @@
 ALTER TABLE reading_target
-ADD COLUMN is_public boolean;
\ No newline at end of file
+ADD COLUMN IF NOT EXISTS is_public boolean;
\ No newline at end of file
@@ -1,2 +1,2 @@
 ALTER TABLE users
-ADD COLUMN display_name VARCHAR(30);
\ No newline at end of file
+ADD COLUMN IF NOT EXISTS display_name VARCHAR(30);
\ No newline at end of file
@@ -0,0 +1,6 @@
+ALTER TABLE reading_target
+DROP COLUMN IF EXISTS calendar_sync_status,
+DROP COLUMN IF EXISTS calendar_sync_error,
+DROP COLUMN IF EXISTS calendar_synced_at;
+
+DROP TABLE IF EXISTS calendar_outbox;
\ No newline at end of file
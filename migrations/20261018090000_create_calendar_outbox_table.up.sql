@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS calendar_outbox (
+    id SERIAL PRIMARY KEY,
+    target_id INT NOT NULL,
+    user_id INT NOT NULL REFERENCES users(id),
+    operation VARCHAR(10) NOT NULL,
+    google_calendar_id VARCHAR(300) NOT NULL DEFAULT '',
+    status VARCHAR(10) NOT NULL DEFAULT 'PENDING',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS calendar_outbox_pending_idx ON calendar_outbox (status, next_attempt_at);
+
+ALTER TABLE reading_target
+ADD COLUMN IF NOT EXISTS calendar_sync_status VARCHAR(10) NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS calendar_sync_error TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS calendar_synced_at TIMESTAMP;
\ No newline at end of file
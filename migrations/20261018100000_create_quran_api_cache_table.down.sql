@@ -0,0 +1 @@
+DROP TABLE IF EXISTS quran_api_cache;
\ No newline at end of file
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS quran_api_cache (
+    cache_key VARCHAR(100) PRIMARY KEY,
+    value TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
\ No newline at end of file
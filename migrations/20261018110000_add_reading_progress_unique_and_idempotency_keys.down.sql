@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS idempotency_keys;
+
+DROP INDEX IF EXISTS reading_progress_user_target_page_key;
\ No newline at end of file
@@ -0,0 +1,16 @@
+DELETE FROM reading_progress a
+USING reading_progress b
+WHERE a.user_id = b.user_id
+AND a.target_id = b.target_id
+AND a.current_page = b.current_page
+AND a.progress_id > b.progress_id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS reading_progress_user_target_page_key ON reading_progress (user_id, target_id, current_page);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    idempotency_key VARCHAR(400) PRIMARY KEY,
+    request_hash VARCHAR(64) NOT NULL,
+    status_code INT NOT NULL DEFAULT 0,
+    response TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE users
+DROP COLUMN IF EXISTS timezone,
+DROP COLUMN IF EXISTS streak_grace_days;
\ No newline at end of file
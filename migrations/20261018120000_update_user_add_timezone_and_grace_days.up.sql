@@ -0,0 +1,3 @@
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS streak_grace_days INT NOT NULL DEFAULT 0;
\ No newline at end of file
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS reading_progress_user_read_at_idx;
+
+ALTER TABLE reading_progress
+DROP COLUMN IF EXISTS read_at;
\ No newline at end of file
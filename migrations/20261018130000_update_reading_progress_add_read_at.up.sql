@@ -0,0 +1,10 @@
+ALTER TABLE reading_progress
+ADD COLUMN IF NOT EXISTS read_at TIMESTAMP;
+
+UPDATE reading_progress SET read_at = last_update_timestamp AT TIME ZONE current_setting('TimeZone') AT TIME ZONE 'UTC' WHERE read_at IS NULL;
+
+ALTER TABLE reading_progress
+ALTER COLUMN read_at SET DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+ALTER COLUMN read_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS reading_progress_user_read_at_idx ON reading_progress (user_id, read_at);
\ No newline at end of file
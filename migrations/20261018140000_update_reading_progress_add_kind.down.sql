@@ -0,0 +1,8 @@
+DELETE FROM reading_progress WHERE kind <> 'read';
+
+DROP INDEX IF EXISTS reading_progress_user_target_page_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS reading_progress_user_target_page_key ON reading_progress (user_id, target_id, current_page);
+
+ALTER TABLE reading_progress
+DROP COLUMN IF EXISTS kind;
\ No newline at end of file
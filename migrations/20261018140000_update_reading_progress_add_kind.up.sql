@@ -0,0 +1,6 @@
+ALTER TABLE reading_progress
+ADD COLUMN IF NOT EXISTS kind VARCHAR(10) NOT NULL DEFAULT 'read';
+
+DROP INDEX IF EXISTS reading_progress_user_target_page_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS reading_progress_user_target_page_key ON reading_progress (user_id, target_id, current_page) WHERE kind = 'read';
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS hifz_reviews;
+
+DROP TABLE IF EXISTS hifz_pages;
\ No newline at end of file
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS hifz_pages (
+    hifz_id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id),
+    page INT NOT NULL,
+    first_verse VARCHAR(10) NOT NULL,
+    last_verse VARCHAR(10) NOT NULL,
+    repetitions INT NOT NULL DEFAULT 0,
+    interval_days INT NOT NULL DEFAULT 0,
+    ease_factor NUMERIC(4, 2) NOT NULL DEFAULT 2.5,
+    due_date DATE NOT NULL,
+    memorized_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_reviewed_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS hifz_pages_user_page_key ON hifz_pages (user_id, page);
+
+CREATE INDEX IF NOT EXISTS hifz_pages_user_due_date_idx ON hifz_pages (user_id, due_date);
+
+CREATE TABLE IF NOT EXISTS hifz_reviews (
+    review_id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id),
+    page INT NOT NULL,
+    quality INT NOT NULL,
+    interval_days INT NOT NULL,
+    ease_factor NUMERIC(4, 2) NOT NULL,
+    due_date DATE NOT NULL,
+    reviewed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS hifz_reviews_user_page_idx ON hifz_reviews (user_id, page);
\ No newline at end of file
@@ -0,0 +1,10 @@
+DELETE FROM reading_progress WHERE start_verse <> '';
+
+DROP INDEX IF EXISTS reading_progress_user_target_page_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS reading_progress_user_target_page_key ON reading_progress (user_id, target_id, current_page) WHERE kind = 'read';
+
+ALTER TABLE reading_progress
+DROP COLUMN IF EXISTS start_verse,
+DROP COLUMN IF EXISTS end_verse,
+DROP COLUMN IF EXISTS page_share;
\ No newline at end of file
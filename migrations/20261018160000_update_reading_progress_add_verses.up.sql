@@ -0,0 +1,8 @@
+ALTER TABLE reading_progress
+ADD COLUMN IF NOT EXISTS start_verse VARCHAR(10) NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS end_verse VARCHAR(10) NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS page_share NUMERIC(7, 4) NOT NULL DEFAULT 1;
+
+DROP INDEX IF EXISTS reading_progress_user_target_page_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS reading_progress_user_target_page_key ON reading_progress (user_id, target_id, current_page) WHERE kind = 'read' AND start_verse = '';
\ No newline at end of file
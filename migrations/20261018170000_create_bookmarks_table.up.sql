@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS bookmarks (
+    user_id INT PRIMARY KEY REFERENCES users(id),
+    target_id INT NOT NULL DEFAULT 0,
+    page INT NOT NULL,
+    verse_key VARCHAR(10) NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS reflections;
\ No newline at end of file
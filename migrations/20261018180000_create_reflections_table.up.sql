@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS reflections (
+    reflection_id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id),
+    progress_id INT REFERENCES reading_progress(progress_id) ON DELETE SET NULL,
+    page INT NOT NULL,
+    verse_key VARCHAR(10) NOT NULL DEFAULT '',
+    note TEXT NOT NULL,
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    is_public BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS reflections_user_created_at_idx ON reflections (user_id, created_at);
+
+CREATE INDEX IF NOT EXISTS reflections_public_created_at_idx ON reflections (created_at) WHERE is_public;
+
+CREATE INDEX IF NOT EXISTS reflections_note_search_idx ON reflections USING GIN (to_tsvector('simple', note));
\ No newline at end of file
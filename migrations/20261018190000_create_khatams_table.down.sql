@@ -0,0 +1 @@
+DROP TABLE IF EXISTS khatams;
\ No newline at end of file
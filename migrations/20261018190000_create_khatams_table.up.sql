@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS khatams (
+    khatam_id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id),
+    target_id INT NOT NULL,
+    target_name VARCHAR(255) NOT NULL DEFAULT '',
+    start_page INT NOT NULL,
+    end_page INT NOT NULL,
+    started_at TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP NOT NULL,
+    duration_days INT NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS khatams_target_id_idx ON khatams (target_id);
+
+CREATE INDEX IF NOT EXISTS khatams_user_completed_at_idx ON khatams (user_id, completed_at);
\ No newline at end of file
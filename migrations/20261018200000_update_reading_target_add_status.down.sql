@@ -0,0 +1,4 @@
+ALTER TABLE reading_target
+DROP COLUMN IF EXISTS status,
+DROP COLUMN IF EXISTS paused_at,
+DROP COLUMN IF EXISTS paused_days;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE reading_target
+ADD COLUMN IF NOT EXISTS status VARCHAR(10) NOT NULL DEFAULT 'active',
+ADD COLUMN IF NOT EXISTS paused_at TIMESTAMP,
+ADD COLUMN IF NOT EXISTS paused_days INT NOT NULL DEFAULT 0;
\ No newline at end of file
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS group_assignments;
+
+DROP TABLE IF EXISTS group_targets;
+
+DROP TABLE IF EXISTS study_group_members;
+
+DROP TABLE IF EXISTS study_groups;
\ No newline at end of file
@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS study_groups (
+    group_id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    owner_id INT NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS study_group_members (
+    group_id INT NOT NULL REFERENCES study_groups(group_id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id),
+    joined_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS study_group_members_user_id_idx ON study_group_members (user_id);
+
+CREATE TABLE IF NOT EXISTS group_targets (
+    group_target_id SERIAL PRIMARY KEY,
+    group_id INT NOT NULL REFERENCES study_groups(group_id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    start_page INT NOT NULL,
+    end_page INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS group_assignments (
+    assignment_id SERIAL PRIMARY KEY,
+    group_target_id INT NOT NULL REFERENCES group_targets(group_target_id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id),
+    target_id INT NOT NULL,
+    start_page INT NOT NULL,
+    end_page INT NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'active'
+);
+
+CREATE INDEX IF NOT EXISTS group_assignments_group_target_id_idx ON group_assignments (group_target_id);
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE reading_target
+DROP COLUMN IF EXISTS recurrence,
+DROP COLUMN IF EXISTS carry_over,
+DROP COLUMN IF EXISTS previous_id,
+DROP COLUMN IF EXISTS next_id;
\ No newline at end of file
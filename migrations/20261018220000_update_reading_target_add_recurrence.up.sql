@@ -0,0 +1,5 @@
+ALTER TABLE reading_target
+ADD COLUMN IF NOT EXISTS recurrence VARCHAR(10) NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS carry_over BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN IF NOT EXISTS previous_id INT,
+ADD COLUMN IF NOT EXISTS next_id INT;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS target_templates;
\ No newline at end of file
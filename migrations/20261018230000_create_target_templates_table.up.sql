@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS target_templates (
+    template_id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    start_page INT NOT NULL,
+    end_page INT NOT NULL,
+    pages INT NOT NULL,
+    duration_days INT NOT NULL,
+    weekday SMALLINT,
+    recurrence VARCHAR(10) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO target_templates (name, description, start_page, end_page, pages, duration_days, weekday, recurrence) VALUES
+('Khatam 30 Hari', 'Seluruh Al-Quran dalam 30 hari', 1, 604, 604, 30, NULL, ''),
+('1 Juz per Hari', 'Satu juz setiap hari, khatam setiap bulan', 1, 604, 604, 30, NULL, 'monthly'),
+('Al-Kahf setiap Jumat', 'Surah Al-Kahf setiap hari Jumat', 293, 304, 12, 1, 5, 'weekly'),
+('Juz Amma dalam 2 Minggu', 'Juz 30 dalam 14 hari', 582, 604, 23, 14, NULL, '');
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE reading_target
+DROP COLUMN IF EXISTS scope;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE reading_target
+ADD COLUMN IF NOT EXISTS scope JSONB;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE calendar_outbox
+ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP;
\ No newline at end of file
@@ -0,0 +1,8 @@
+package migrations
+
+import "embed"
+
+// FS holds every .up.sql/.down.sql pair so the binary can migrate itself.
+//
+//go:embed *.sql
+var FS embed.FS
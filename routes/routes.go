@@ -32,6 +32,12 @@ func RegisterRoutes(router *mux.Router) {
 	adminRoute.HandleFunc("/users", handlers.GetAllUsers).Methods(http.MethodGet)
 	
 	generalRoute.HandleFunc("/users/{id}", handlers.UpdateUser).Methods(http.MethodPut)
+	generalRoute.HandleFunc("/users/{id}/streaks", handlers.GetReadingStreaks).Methods(http.MethodGet)
+	generalRoute.HandleFunc("/users/{id}/stats", handlers.GetReadingStats).Methods(http.MethodGet)
+	generalRoute.HandleFunc("/users/{id}/khatams", handlers.GetKhatamHistory).Methods(http.MethodGet)
+	generalRoute.HandleFunc("/users/{id}/bookmark", handlers.GetBookmark).Methods(http.MethodGet)
+	generalRoute.HandleFunc("/users/{id}/bookmark", handlers.PinBookmark).Methods(http.MethodPut)
+	generalRoute.HandleFunc("/users/{id}/bookmark", handlers.UnpinBookmark).Methods(http.MethodDelete)
 	adminRoute.HandleFunc("/users/{id}", handlers.DeleteUser).Methods(http.MethodDelete)
 
 	// reading target
@@ -42,18 +48,59 @@ func RegisterRoutes(router *mux.Router) {
 	generalRoute.HandleFunc("/reading-targets/{id}", handlers.GetReadingTargetByID).Methods(http.MethodGet)
 	generalRoute.HandleFunc("/reading-targets/{id}", handlers.UpdateReadingTargetByID).Methods(http.MethodPut)
 	generalRoute.HandleFunc("/reading-targets/{id}", handlers.DeleteReadingTarget).Methods(http.MethodDelete)
+	generalRoute.HandleFunc("/reading-targets/{id}/plan", handlers.GetReadingPlan).Methods(http.MethodGet)
+	generalRoute.HandleFunc("/reading-targets/{id}/pause", handlers.PauseReadingTarget).Methods(http.MethodPost)
+	generalRoute.HandleFunc("/reading-targets/{id}/resume", handlers.ResumeReadingTarget).Methods(http.MethodPost)
+
+	// target templates
+	generalRoute.HandleFunc("/target-templates", handlers.GetTargetTemplates).Methods(http.MethodGet)
+	adminRoute.HandleFunc("/target-templates", handlers.CreateTargetTemplate).Methods(http.MethodPost)
+	adminRoute.HandleFunc("/target-templates/{tid}", handlers.DeleteTargetTemplate).Methods(http.MethodDelete)
+	generalRoute.HandleFunc("/users/{id}/target-templates/{tid}/reading-targets", handlers.CreateReadingTargetFromTemplate).Methods(http.MethodPost)
 
 	// reading progress
 	generalRoute.HandleFunc("/users/{id}/reading-progress", handlers.GetAllReadingProgressByUserID).Methods(http.MethodGet)
 	generalRoute.HandleFunc("/users/{id}/reading-targets/{tid}/reading-progress", handlers.GetAllReadingProgressByUserIDTargetID).Methods(http.MethodGet)
-	generalRoute.HandleFunc("/users/{id}/reading-targets/{tid}/reading-progress", handlers.CreateReadingProgress).Methods(http.MethodPost)
+	generalRoute.HandleFunc("/users/{id}/reading-targets/{tid}/reading-progress", handlers.Idempotent(handlers.CreateReadingProgress)).Methods(http.MethodPost)
+	generalRoute.HandleFunc("/users/{id}/reading-targets/{tid}/reading-progress/bulk", handlers.Idempotent(handlers.CreateBulkReadingProgress)).Methods(http.MethodPost)
 
 	adminRoute.HandleFunc("/reading-progress", handlers.GetAllReadingProgress).Methods(http.MethodGet)
 	generalRoute.HandleFunc("/reading-progress/{id}", handlers.GetReadingProgressByID).Methods(http.MethodGet)
 	generalRoute.HandleFunc("/reading-progress/{id}", handlers.UpdateReadingProgressByID).Methods(http.MethodPut)
 	generalRoute.HandleFunc("/reading-progress/{id}", handlers.DeleteReadingProgress).Methods(http.MethodDelete)
 
+	// groups
+	generalRoute.HandleFunc("/users/{id}/groups", handlers.CreateGroup).Methods(http.MethodPost)
+	generalRoute.HandleFunc("/users/{id}/groups", handlers.GetGroups).Methods(http.MethodGet)
+	generalRoute.HandleFunc("/users/{id}/groups/{gid}", handlers.GetGroupByID).Methods(http.MethodGet)
+	generalRoute.HandleFunc("/users/{id}/groups/{gid}", handlers.DeleteGroup).Methods(http.MethodDelete)
+	generalRoute.HandleFunc("/users/{id}/groups/{gid}/members", handlers.AddGroupMember).Methods(http.MethodPost)
+	generalRoute.HandleFunc("/users/{id}/groups/{gid}/members/{username}", handlers.RemoveGroupMember).Methods(http.MethodDelete)
+	generalRoute.HandleFunc("/users/{id}/groups/{gid}/targets", handlers.CreateGroupTarget).Methods(http.MethodPost)
+	generalRoute.HandleFunc("/users/{id}/groups/{gid}/targets", handlers.GetGroupTargets).Methods(http.MethodGet)
+	generalRoute.HandleFunc("/users/{id}/groups/{gid}/targets/{gtid}", handlers.GetGroupTargetByID).Methods(http.MethodGet)
+	generalRoute.HandleFunc("/users/{id}/groups/{gid}/targets/{gtid}/assignments/{aid}/reassign", handlers.ReassignGroupAssignment).Methods(http.MethodPost)
+
+	// reflections
+	generalRoute.HandleFunc("/users/{id}/reflections", handlers.CreateReflection).Methods(http.MethodPost)
+	generalRoute.HandleFunc("/users/{id}/reflections", handlers.GetReflections).Methods(http.MethodGet)
+	generalRoute.HandleFunc("/users/{id}/reflections/export", handlers.ExportReflections).Methods(http.MethodGet)
+	generalRoute.HandleFunc("/users/{id}/reflections/{rid:[0-9]+}", handlers.GetReflectionByID).Methods(http.MethodGet)
+	generalRoute.HandleFunc("/users/{id}/reflections/{rid:[0-9]+}", handlers.UpdateReflection).Methods(http.MethodPut)
+	generalRoute.HandleFunc("/users/{id}/reflections/{rid:[0-9]+}", handlers.DeleteReflection).Methods(http.MethodDelete)
+	generalRoute.HandleFunc("/reflections", handlers.GetPublicReflections).Methods(http.MethodGet)
+
+	// hifz
+	generalRoute.HandleFunc("/users/{id}/hifz", handlers.MarkHifzPages).Methods(http.MethodPost)
+	generalRoute.HandleFunc("/users/{id}/hifz", handlers.GetHifzPages).Methods(http.MethodGet)
+	generalRoute.HandleFunc("/users/{id}/hifz/due", handlers.GetHifzDue).Methods(http.MethodGet)
+	generalRoute.HandleFunc("/users/{id}/hifz/reviews", handlers.Idempotent(handlers.ReviewHifzPage)).Methods(http.MethodPost)
+	generalRoute.HandleFunc("/users/{id}/hifz/{page:[0-9]+}", handlers.DeleteHifzPage).Methods(http.MethodDelete)
+	generalRoute.HandleFunc("/users/{id}/hifz/{page:[0-9]+}/reviews", handlers.GetHifzPageReviews).Methods(http.MethodGet)
+
 	generalRoute.HandleFunc("/page-info/{pageNum}", handlers.GetPageInfoByPageNumber).Methods(http.MethodGet)
+	generalRoute.HandleFunc("/hijri/{year:[0-9]+}/{month:[0-9]+}", handlers.GetHijriMonth).Methods(http.MethodGet)
+	adminRoute.HandleFunc("/quran-cache/stats", handlers.GetQuranCacheStats).Methods(http.MethodGet)
 	generalRoute.HandleFunc("/leaderboard", handlers.GetLeaderboard).Methods(http.MethodGet)
 	// Add more routes as needed
 }
\ No newline at end of file